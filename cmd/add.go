@@ -5,6 +5,7 @@ import (
 
 	"github.com/calghar/gh-account-switcher/internal/config"
 	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 	"github.com/calghar/gh-account-switcher/internal/ssh"
 	"github.com/spf13/cobra"
 )
@@ -12,16 +13,34 @@ import (
 var addCmd = &cobra.Command{
 	Use:   "add <name> <email> [git-name] [gpg-key]",
 	Short: "Add a new profile",
-	Long: `Add a new GitHub profile with specified email and optional Git name and GPG key.
+	Long: `Add a new profile with specified email and optional Git name and GPG key.
+
+Profiles use github.com unless --host names another server, such as a GitHub
+Enterprise Server, GitLab, Bitbucket or Gitea instance. More hosts can be
+added later with 'gh-switch host add'.
+
+A GPG key enables OpenPGP commit signing; use 'gh-switch signing set' for SSH
+or X.509 signing. The key must be in your keyring with its secret part, must
+be able to sign and must not be expired or revoked; use --skip-key-check to
+add the profile anyway.
 
 Examples:
   gh-switch add work john.doe@company.com "John Doe" ABC123DEF456
-  gh-switch add personal john@gmail.com "Johnny Smith"`,
+  gh-switch add personal john@gmail.com "Johnny Smith"
+  gh-switch add corp john@corp.com "John Doe" --host gitlab.corp.com --port 2222`,
 	Args: cobra.RangeArgs(2, 4),
-	RunE: runAdd,
+	RunE: mutating(runAdd),
 }
 
+var (
+	addHostName     string
+	addSkipKeyCheck bool
+)
+
 func init() {
+	addCmd.Flags().StringVar(&addHostName, "host", config.DefaultHostName, "Git host the profile authenticates against")
+	addCmd.Flags().BoolVar(&addSkipKeyCheck, "skip-key-check", false, "Add the profile even if the GPG key fails verification")
+	addHostFlags(addCmd)
 	rootCmd.AddCommand(addCmd)
 }
 
@@ -61,7 +80,14 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		Emails:       []string{email},
 		PrimaryEmail: email,
 		GitName:      gitName,
-		GPGKey:       gpgKey,
+		Hosts:        []config.Host{hostFromFlags(addHostName)},
+	}
+	if gpgKey != "" {
+		profile.Signing = &config.Signing{
+			Format:  config.SigningFormatOpenPGP,
+			Key:     gpgKey,
+			Commits: true,
+		}
 	}
 
 	// Validate and add profile
@@ -69,23 +95,39 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to add profile: %w", err)
 	}
 
-	// Save configuration
-	if err := configMgr.Save(cfg); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+	// Verify the signing key before any commit gets signed with it
+	keyIssues, err := checkSigningKey(profile)
+	if err != nil {
+		return fmt.Errorf("failed to verify GPG key: %w", err)
+	}
+	for _, issue := range keyIssues {
+		if issue.Blocking && !addSkipKeyCheck {
+			return fmt.Errorf("%s (use --skip-key-check to add the profile anyway)", issue.Message)
+		}
 	}
 
-	// Setup SSH config entry
 	sshMgr, err := ssh.NewConfigManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize SSH manager: %w", err)
 	}
 
-	if err := sshMgr.EnsureProfileEntry(profile); err != nil {
-		fmt.Printf("Warning: Failed to setup SSH config: %v\n", err)
-	} else {
-		hostAlias := ssh.GetHostAlias(profileName)
-		fmt.Printf("✓ SSH config entry created\n")
-		fmt.Printf("  Use this host in git URLs: git@%s:user/repo.git\n", hostAlias)
+	// Plan the SSH config entry and the config save together so they are
+	// applied all-or-nothing; the SSH step also fills in the key path
+	p := plan.New()
+	if err := sshMgr.PlanEnsureProfileEntry(p, profile); err != nil {
+		return fmt.Errorf("failed to plan SSH config: %w", err)
+	}
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ SSH config entry created\n")
+	for _, host := range profile.HostList() {
+		fmt.Printf("  Use this host in git URLs: %s\n", host.CloneURL(profileName, "user/repo"))
 	}
 
 	// Success message
@@ -97,12 +139,15 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	if gpgKey != "" {
 		fmt.Printf("  GPG key: %s\n", gpgKey)
 	}
+	for _, issue := range keyIssues {
+		fmt.Printf("\n⚠ %s\n", issue.Message)
+	}
 
 	// Check if SSH key exists
-	sshKeyPath := ssh.GetSSHKeyPath(profileName)
+	sshKeyPath := profile.SSHKeyPath
 	if !ssh.CheckSSHKeyExists(sshKeyPath) {
 		fmt.Printf("\n⚠ SSH key not found: %s\n", sshKeyPath)
-		fmt.Printf("  Generate one with: ssh-keygen -t ed25519 -f %s -C \"%s\"\n", sshKeyPath, email)
+		fmt.Printf("  Generate one with: gh-switch keygen %s\n", profileName)
 	}
 
 	// Suggest next steps
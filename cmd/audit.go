@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/calghar/gh-account-switcher/internal/audit"
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSince   string
+	auditAuthors []string
+	auditFormat  string
+	auditWorkers int
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit [path]",
+	Short: "Flag commits made with the wrong identity or without a signature",
+	Long: `Read the history of the Git repositories under path (default: the current
+directory) and flag commits that don't match the profile the rules select:
+
+  other-profile   the author or committer email belongs to another profile
+  unknown-email   the author or committer email belongs to no profile
+  unsigned        the commit is unsigned, but the profile signs commits
+
+Results are grouped by profile and repository. Repositories no rule covers
+are listed but not audited. Commits committed by GitHub's web interface
+(noreply@github.com) are checked by author only.
+
+The command exits with an error if any commit is flagged, so it can gate
+compliance jobs; use --format csv or json for review spreadsheets and tools.
+
+Examples:
+  gh-switch audit ~/work
+  gh-switch audit ~/work --since 2024-01-01 --format csv > audit.csv
+  gh-switch audit --author me@corp.com --author me@home.com`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runAudit,
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "Only audit commits newer than a date, e.g. 2024-01-01 or \"3 months ago\"")
+	auditCmd.Flags().StringArrayVar(&auditAuthors, "author", nil, "Only audit commits whose author matches a pattern (repeatable)")
+	auditCmd.Flags().StringVar(&auditFormat, "format", "table", "Output format: table, json or csv")
+	auditCmd.Flags().IntVar(&auditWorkers, "workers", runtime.NumCPU(), "Number of repositories read in parallel")
+	rootCmd.AddCommand(auditCmd)
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	if auditFormat != "table" && auditFormat != "json" && auditFormat != "csv" {
+		return fmt.Errorf("unknown format '%s' (expected table, json or csv)", auditFormat)
+	}
+
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	if _, err := os.Stat(absRoot); err != nil {
+		return fmt.Errorf("path not found: %s", absRoot)
+	}
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	paths, err := git.FindRepositories(absRoot)
+	if err != nil {
+		return err
+	}
+	// Inside a repository, audit the repository itself
+	if len(paths) == 0 {
+		if _, err := git.RepoGitDir(absRoot); err == nil {
+			paths = []string{absRoot}
+		}
+	}
+
+	repos := audit.Run(cfg, paths, audit.Options{
+		Since:   auditSince,
+		Authors: auditAuthors,
+		Workers: auditWorkers,
+	})
+
+	switch auditFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(repos); err != nil {
+			return fmt.Errorf("failed to encode results: %w", err)
+		}
+	case "csv":
+		if err := writeAuditCSV(repos); err != nil {
+			return err
+		}
+	default:
+		printAuditReport(absRoot, repos)
+	}
+
+	flagged := 0
+	for _, repo := range repos {
+		flagged += repo.FlaggedCommits()
+	}
+	if flagged > 0 {
+		return fmt.Errorf("audit flagged %d commit(s)", flagged)
+	}
+	return nil
+}
+
+// printAuditReport prints the findings grouped by profile, then repository
+func printAuditReport(root string, repos []audit.Repository) {
+	if len(repos) == 0 {
+		fmt.Printf("No Git repositories found under %s\n", root)
+		return
+	}
+
+	var uncovered []audit.Repository
+	commits, flagged := 0, 0
+	profile, grouped := "", false
+	for _, repo := range repos {
+		if repo.Profile == "" && repo.Error == "" {
+			uncovered = append(uncovered, repo)
+			continue
+		}
+
+		if !grouped || repo.Profile != profile {
+			profile, grouped = repo.Profile, true
+			if profile == "" {
+				fmt.Println("\nUnresolved:")
+			} else {
+				fmt.Printf("\nProfile '%s':\n", profile)
+			}
+		}
+
+		if repo.Error != "" {
+			fmt.Printf("  ⚠ %s: %s\n", relativeTo(root, repo.Path), repo.Error)
+			continue
+		}
+
+		count := repo.FlaggedCommits()
+		commits += repo.Commits
+		flagged += count
+
+		marker := "✓"
+		if count > 0 {
+			marker = "✗"
+		}
+		fmt.Printf("  %s %s (%s): %d commit(s), %d flagged\n", marker, relativeTo(root, repo.Path), repo.Rule, repo.Commits, count)
+		for _, finding := range repo.Findings {
+			fmt.Printf("      %s %s  %s  %s\n", shortHash(finding.Commit), finding.Date, finding.Detail, finding.Subject)
+		}
+	}
+
+	if len(uncovered) > 0 {
+		fmt.Println("\nNot covered by any rule (not audited):")
+		for _, repo := range uncovered {
+			fmt.Printf("  • %s\n", relativeTo(root, repo.Path))
+		}
+	}
+
+	fmt.Printf("\n%d commit(s) in %d repositories audited, %d flagged\n", commits, len(repos)-len(uncovered), flagged)
+}
+
+// writeAuditCSV writes one row per finding
+func writeAuditCSV(repos []audit.Repository) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"repository", "profile", "rule", "commit", "date", "author_email", "committer_email", "signature", "issue", "detail", "subject"})
+	for _, repo := range repos {
+		for _, f := range repo.Findings {
+			w.Write([]string{repo.Path, repo.Profile, repo.Rule, f.Commit, f.Date, f.AuthorEmail, f.CommitterEmail, f.Signature, f.Issue, f.Detail, f.Subject})
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
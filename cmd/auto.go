@@ -6,46 +6,85 @@ import (
 
 	"github.com/calghar/gh-account-switcher/internal/config"
 	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 	"github.com/spf13/cobra"
 )
 
+var (
+	autoRemote     bool
+	autoIgnoreCase bool
+	autoExclude    []string
+
+	removeExclusion bool
+)
+
 var autoCmd = &cobra.Command{
 	Use:   "auto <directory> <profile>",
 	Short: "Setup automatic profile switching for a directory",
 	Long: `Configure automatic profile switching based on directory location.
 
 This uses Git's includeIf feature to automatically use different profiles
-for different project directories.
+for different project directories. A rule covers the directory and everything
+below it, matched by whole path segments: ~/work does not cover ~/workshop.
+
+The directory may be a glob pattern, quoted so the shell leaves it alone:
+'*' matches within one path segment and '**' across several. --ignore-case
+matches the path case-insensitively (gitdir/i). --exclude leaves a directory
+below the rule's path out of it; repositories there fall back to the next
+matching rule or the global identity. Running auto again for the same
+directory updates the rule and adds further exclusions.
+
+With --remote, the first argument is a remote URL pattern instead, and the
+profile is used for any repository with a matching remote, wherever it is
+cloned (hasconfig:remote.*.url, git 2.36 or newer). Patterns use git's
+wildmatch syntax: '*' stays within a path segment, '**' spans several.
+Remote rules take precedence over directory rules.
 
 Examples:
   gh-switch auto ~/projects/work work
-  gh-switch auto ~/projects/personal personal`,
+  gh-switch auto ~/projects/personal personal
+  gh-switch auto '~/clients/*/internal' work
+  gh-switch auto ~/work work --exclude ~/work/oss
+  gh-switch auto ~/Work work --ignore-case
+  gh-switch auto --remote 'https://github.com/acme/**' work
+  gh-switch auto --remote 'git@github.com:acme/**' work`,
 	Args: cobra.ExactArgs(2),
-	RunE: runAuto,
+	RunE: mutating(runAuto),
 }
 
 var autoListCmd = &cobra.Command{
 	Use:   "auto-list",
-	Short: "List all directory rules",
-	Long:  `List all configured directory-to-profile mappings.`,
+	Short: "List all directory and remote rules",
+	Long:  `List all configured directory-to-profile and remote-to-profile mappings.`,
 	RunE:  runAutoList,
 }
 
 var autoRemoveCmd = &cobra.Command{
 	Use:   "auto-remove <directory>",
 	Short: "Remove a directory rule",
-	Long:  `Remove automatic profile switching for a directory.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runAutoRemove,
+	Long: `Remove automatic profile switching for a directory, or with --remote, the
+remote rule for a URL pattern. With --exclude, the argument is an excluded
+directory, which is put back under its rule.`,
+	Args: cobra.ExactArgs(1),
+	RunE: mutating(runAutoRemove),
 }
 
 func init() {
+	autoCmd.Flags().BoolVar(&autoRemote, "remote", false, "Match repositories by remote URL pattern instead of directory")
+	autoCmd.Flags().BoolVar(&autoIgnoreCase, "ignore-case", false, "Match the directory case-insensitively")
+	autoCmd.Flags().StringArrayVar(&autoExclude, "exclude", nil, "Directory below the rule's path to leave out (repeatable)")
+	autoRemoveCmd.Flags().BoolVar(&autoRemote, "remote", false, "Remove the remote rule for a URL pattern")
+	autoRemoveCmd.Flags().BoolVar(&removeExclusion, "exclude", false, "Stop excluding a directory from its rule")
 	rootCmd.AddCommand(autoCmd)
 	rootCmd.AddCommand(autoListCmd)
 	rootCmd.AddCommand(autoRemoveCmd)
 }
 
 func runAuto(cmd *cobra.Command, args []string) error {
+	if autoRemote {
+		return runAutoRemote(args[0], args[1])
+	}
+
 	directory := args[0]
 	profileName := args[1]
 
@@ -61,14 +100,16 @@ func runAuto(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Verify directory exists
-	if info, err := os.Stat(directory); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("directory does not exist: %s", directory)
+	// Verify directory exists; glob patterns may match directories created later
+	if !config.IsGlob(directory) {
+		if info, err := os.Stat(directory); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("directory does not exist: %s", directory)
+			}
+			return fmt.Errorf("failed to check directory: %w", err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("path is not a directory: %s", directory)
 		}
-		return fmt.Errorf("failed to check directory: %w", err)
-	} else if !info.IsDir() {
-		return fmt.Errorf("path is not a directory: %s", directory)
 	}
 
 	// Get profile to verify it exists
@@ -77,14 +118,23 @@ func runAuto(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("profile not found: %w", err)
 	}
 
-	// Add directory rule
-	if err := cfg.AddDirectoryRule(directory, profileName); err != nil {
-		return fmt.Errorf("failed to add directory rule: %w", err)
+	// The includeIf directives of an existing rule are replaced, since its
+	// exclusions or case sensitivity may change
+	var previous *config.DirectoryRule
+	if existing, err := cfg.FindDirectoryRule(directory); err == nil {
+		copied := *existing
+		previous = &copied
 	}
 
-	// Save configuration
-	if err := configMgr.Save(cfg); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+	// Add directory rule
+	rule, err := cfg.AddDirectoryRule(config.DirectoryRule{
+		Path:       directory,
+		Profile:    profileName,
+		IgnoreCase: autoIgnoreCase,
+		Exclude:    autoExclude,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add directory rule: %w", err)
 	}
 
 	// Setup Git includeIf configuration
@@ -93,12 +143,28 @@ func runAuto(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize git manager: %w", err)
 	}
 
-	if err := gitMgr.SetupProfile(profile, directory); err != nil {
-		return fmt.Errorf("failed to setup Git includeIf: %w", err)
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+	if previous != nil {
+		if err := gitMgr.PlanRemoveDirectoryIncludeIf(p, *previous); err != nil {
+			return fmt.Errorf("failed to plan includeIf removal: %w", err)
+		}
+	}
+	if err := gitMgr.PlanSetupDirectoryRule(p, profile, *rule); err != nil {
+		return fmt.Errorf("failed to plan Git includeIf: %w", err)
+	}
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
 	}
 
 	fmt.Printf("✓ Directory rule added successfully!\n")
-	fmt.Printf("  Directory: %s\n", directory)
+	fmt.Printf("  Directory: %s\n", rule.Path)
+	for _, exclude := range rule.Exclude {
+		fmt.Printf("  Excluding: %s\n", exclude)
+	}
 	fmt.Printf("  Profile: %s\n", profileName)
 	fmt.Printf("  Email: %s\n", profile.PrimaryEmail)
 	fmt.Println("\nGit will now automatically use this profile for repositories in this directory.")
@@ -118,34 +184,64 @@ func runAutoList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	if len(cfg.DirectoryRules) == 0 {
+	if len(cfg.DirectoryRules) == 0 && len(cfg.RemoteRules) == 0 {
 		fmt.Println("No directory rules configured yet.")
 		fmt.Println("\nAdd a rule with: gh-switch auto <directory> <profile>")
 		return nil
 	}
 
-	fmt.Printf("Directory rules (%d):\n\n", len(cfg.DirectoryRules))
-
-	for _, rule := range cfg.DirectoryRules {
-		profile, err := cfg.GetProfile(rule.Profile)
-		if err != nil {
-			fmt.Printf("  %s → %s (profile not found!)\n", rule.Path, rule.Profile)
-			continue
+	if len(cfg.DirectoryRules) > 0 {
+		fmt.Printf("Directory rules (%d):\n\n", len(cfg.DirectoryRules))
+		for _, rule := range cfg.DirectoryRules {
+			var notes []string
+			if rule.IgnoreCase {
+				notes = append(notes, "Case-insensitive")
+			}
+			for _, exclude := range rule.Exclude {
+				notes = append(notes, "Excluding: "+exclude)
+			}
+			printRule(cfg, rule.Path, rule.Profile, notes...)
 		}
+	}
 
-		fmt.Printf("  %s\n", rule.Path)
-		fmt.Printf("    → Profile: %s\n", rule.Profile)
-		fmt.Printf("    → Email: %s\n", profile.PrimaryEmail)
-		if profile.GitName != "" {
-			fmt.Printf("    → Name: %s\n", profile.GitName)
+	if len(cfg.RemoteRules) > 0 {
+		fmt.Printf("Remote rules (%d):\n\n", len(cfg.RemoteRules))
+		for _, rule := range cfg.RemoteRules {
+			printRule(cfg, rule.Pattern, rule.Profile)
 		}
-		fmt.Println()
 	}
 
 	return nil
 }
 
+// printRule prints a directory or remote rule with its profile's identity
+func printRule(cfg *config.Config, match, profileName string, notes ...string) {
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		fmt.Printf("  %s → %s (profile not found!)\n", match, profileName)
+		return
+	}
+
+	fmt.Printf("  %s\n", match)
+	fmt.Printf("    → Profile: %s\n", profileName)
+	fmt.Printf("    → Email: %s\n", profile.PrimaryEmail)
+	if profile.GitName != "" {
+		fmt.Printf("    → Name: %s\n", profile.GitName)
+	}
+	for _, note := range notes {
+		fmt.Printf("    → %s\n", note)
+	}
+	fmt.Println()
+}
+
 func runAutoRemove(cmd *cobra.Command, args []string) error {
+	if autoRemote {
+		return runAutoRemoteRemove(args[0])
+	}
+	if removeExclusion {
+		return runAutoExclusionRemove(args[0])
+	}
+
 	directory := args[0]
 
 	configMgr, err := config.NewConfigManager()
@@ -158,19 +254,185 @@ func runAutoRemove(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	rule, err := cfg.FindDirectoryRule(directory)
+	if err != nil {
+		return fmt.Errorf("failed to remove directory rule: %w", err)
+	}
+	removed := *rule
+
 	// Remove directory rule
 	if err := cfg.RemoveDirectoryRule(directory); err != nil {
 		return fmt.Errorf("failed to remove directory rule: %w", err)
 	}
 
-	// Save configuration
-	if err := configMgr.Save(cfg); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+	if err := gitMgr.PlanRemoveDirectoryIncludeIf(p, removed); err != nil {
+		return fmt.Errorf("failed to plan includeIf removal: %w", err)
+	}
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Directory rule removed: %s\n", removed.Path)
+	fmt.Println("  The rule's Git includeIf directives were removed from your global .gitconfig.")
+
+	return nil
+}
+
+// runAutoExclusionRemove puts an excluded directory back under its rule
+func runAutoExclusionRemove(directory string) error {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	previous, err := cfg.RemoveExclusion(directory)
+	if err != nil {
+		return err
+	}
+
+	rule, err := cfg.FindDirectoryRule(previous.Path)
+	if err != nil {
+		return err
+	}
+
+	profile, err := cfg.GetProfile(rule.Profile)
+	if err != nil {
+		return err
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+	if err := gitMgr.PlanRemoveDirectoryIncludeIf(p, previous); err != nil {
+		return fmt.Errorf("failed to plan includeIf removal: %w", err)
+	}
+	if err := gitMgr.PlanSetupDirectoryRule(p, profile, *rule); err != nil {
+		return fmt.Errorf("failed to plan Git includeIf: %w", err)
+	}
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ %s is no longer excluded from the rule for %s\n", directory, rule.Path)
+	return nil
+}
+
+// runAutoRemote adds a remote rule
+func runAutoRemote(pattern, profileName string) error {
+	if err := git.CheckRemoteRuleSupport(); err != nil {
+		return err
+	}
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return fmt.Errorf("profile not found: %w", err)
+	}
+
+	if err := cfg.AddRemoteRule(pattern, profileName); err != nil {
+		return fmt.Errorf("failed to add remote rule: %w", err)
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+	if err := gitMgr.PlanSetupRemoteRule(p, profile, pattern); err != nil {
+		return fmt.Errorf("failed to plan Git includeIf: %w", err)
+	}
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Remote rule added successfully!\n")
+	fmt.Printf("  Remote URL: %s\n", pattern)
+	fmt.Printf("  Profile: %s\n", profileName)
+	fmt.Printf("  Email: %s\n", profile.PrimaryEmail)
+	fmt.Println("\nGit will now use this profile for repositories with a matching remote, wherever they are.")
+	fmt.Println("Note: The pattern must match the remote URL as configured, before any insteadOf rewriting.")
+
+	return nil
+}
+
+// runAutoRemoteRemove removes a remote rule
+func runAutoRemoteRemove(pattern string) error {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var profileName string
+	for _, rule := range cfg.RemoteRules {
+		if rule.Pattern == pattern {
+			profileName = rule.Profile
+		}
+	}
+
+	if err := cfg.RemoveRemoteRule(pattern); err != nil {
+		return fmt.Errorf("failed to remove remote rule: %w", err)
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+	if err := gitMgr.PlanRemoveManagedIncludeIf(p, git.RemoteCondition(pattern), profileName); err != nil {
+		return fmt.Errorf("failed to plan includeIf removal: %w", err)
+	}
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
 	}
 
-	fmt.Printf("✓ Directory rule removed: %s\n", directory)
-	fmt.Println("\nNote: The Git includeIf directive remains in your global .gitconfig")
-	fmt.Println("but will point to the profile config file which is still available.")
+	fmt.Printf("✓ Remote rule removed: %s\n", pattern)
+	fmt.Println("  The matching Git includeIf directive was removed from your global .gitconfig.")
 
 	return nil
 }
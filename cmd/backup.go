@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/backup"
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/hooks"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/calghar/gh-account-switcher/internal/ssh"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Inspect and restore snapshots of managed files",
+	Long: `gh-switch snapshots config.json, ~/.gitconfig, the generated
+~/.gitconfig-<profile> files, ~/.ssh/config, the profiles' SSH key pairs and
+the managed hook scripts before every command that changes them. Use these commands to inspect and roll back those snapshots.
+
+Examples:
+  gh-switch backup list
+  gh-switch backup show latest
+  gh-switch backup restore 20250101-120000.000`,
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded snapshots",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupList,
+}
+
+var backupShowCmd = &cobra.Command{
+	Use:   "show <id|latest>",
+	Short: "Show the files recorded in a snapshot",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBackupShow,
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <id|latest>",
+	Short: "Restore all files recorded in a snapshot",
+	Long: `Restore every file recorded in a snapshot to its recorded state.
+
+Files that did not exist when the snapshot was taken are deleted. The current
+state is snapshotted first, so a restore can itself be undone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: mutating(runBackupRestore),
+}
+
+func init() {
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupShowCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+	rootCmd.AddCommand(backupCmd)
+}
+
+// managedFiles returns every file gh-switch may modify
+func managedFiles() ([]string, error) {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	sshMgr, err := ssh.NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize SSH manager: %w", err)
+	}
+
+	hooksMgr, err := hooks.NewManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize hooks manager: %w", err)
+	}
+
+	files := []string{
+		configMgr.ConfigFile(),
+		gitMgr.GlobalConfigPath(),
+		sshMgr.ConfigPath(),
+	}
+	files = append(files, hooksMgr.Files()...)
+
+	// Profile configs and key pairs known to config.json, plus any profile
+	// configs left behind on disk
+	if cfg, err := configMgr.Load(); err == nil {
+		for name, profile := range cfg.Profiles {
+			keyPath := ssh.KeyPath(profile)
+			files = append(files, gitMgr.ProfileConfigPath(name), keyPath, keyPath+".pub")
+		}
+	}
+	if matches, err := filepath.Glob(gitMgr.ProfileConfigPath("*")); err == nil {
+		files = append(files, matches...)
+	}
+
+	return files, nil
+}
+
+// snapshotManagedFiles records the current state of every managed file
+func snapshotManagedFiles(command string) error {
+	files, err := managedFiles()
+	if err != nil {
+		return err
+	}
+
+	backupMgr, err := backup.NewManager()
+	if err != nil {
+		return err
+	}
+
+	_, err = backupMgr.Create(strings.TrimSpace(command), files)
+	return err
+}
+
+func runBackupList(cmd *cobra.Command, args []string) error {
+	backupMgr, err := backup.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize backup manager: %w", err)
+	}
+
+	snapshots, err := backupMgr.List()
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) == 0 {
+		fmt.Println("No snapshots recorded yet.")
+		return nil
+	}
+
+	fmt.Printf("Snapshots (%d, newest first):\n\n", len(snapshots))
+	for _, snapshot := range snapshots {
+		fmt.Printf("  %s  %s\n", snapshot.ID, snapshot.Command)
+	}
+
+	return nil
+}
+
+func runBackupShow(cmd *cobra.Command, args []string) error {
+	backupMgr, err := backup.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize backup manager: %w", err)
+	}
+
+	snapshot, err := backupMgr.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Snapshot: %s\n", snapshot.ID)
+	fmt.Printf("  Taken: %s\n", snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Before: %s\n", snapshot.Command)
+	fmt.Println("\nFiles:")
+
+	for _, entry := range snapshot.Files {
+		status, err := snapshotEntryStatus(backupMgr, snapshot, entry)
+		if err != nil {
+			return err
+		}
+
+		if entry.Exists {
+			fmt.Printf("  %s (%d bytes) - %s\n", entry.Path, entry.Size, status)
+		} else {
+			fmt.Printf("  %s (absent) - %s\n", entry.Path, status)
+		}
+	}
+
+	return nil
+}
+
+// snapshotEntryStatus describes how a recorded file compares to its current state
+func snapshotEntryStatus(backupMgr *backup.Manager, snapshot *backup.Snapshot, entry backup.FileEntry) (string, error) {
+	current, err := os.ReadFile(entry.Path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", entry.Path, err)
+	}
+
+	switch {
+	case !entry.Exists && !exists:
+		return "unchanged", nil
+	case !entry.Exists:
+		return "created since", nil
+	case !exists:
+		return "deleted since", nil
+	}
+
+	stored, err := backupMgr.ReadFile(snapshot, entry)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(stored, current) {
+		return "unchanged", nil
+	}
+	return "modified since", nil
+}
+
+func runBackupRestore(cmd *cobra.Command, args []string) error {
+	backupMgr, err := backup.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize backup manager: %w", err)
+	}
+
+	snapshot, err := backupMgr.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	// "latest" now refers to the snapshot mutating() just took, so resolve
+	// it to the one before that. Dry runs take no snapshot.
+	if args[0] == "latest" && !dryRun {
+		snapshots, err := backupMgr.List()
+		if err != nil {
+			return err
+		}
+		if len(snapshots) < 2 {
+			return fmt.Errorf("no earlier snapshot to restore")
+		}
+		snapshot = snapshots[1]
+	}
+
+	// Only files that differ from the snapshot need restoring
+	var changed []backup.FileEntry
+	for _, entry := range snapshot.Files {
+		status, err := snapshotEntryStatus(backupMgr, snapshot, entry)
+		if err != nil {
+			return err
+		}
+		if status != "unchanged" {
+			changed = append(changed, entry)
+		}
+	}
+
+	if len(changed) == 0 {
+		fmt.Printf("Nothing to restore: all files match snapshot %s.\n", snapshot.ID)
+		return nil
+	}
+
+	if !skipPrompts && !dryRun {
+		fmt.Printf("Restore %d file(s) from snapshot %s (taken before '%s')? (y/N): ", len(changed), snapshot.ID, snapshot.Command)
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" {
+			fmt.Println("Restore cancelled.")
+			return nil
+		}
+	}
+
+	p := plan.New()
+	if err := backupMgr.PlanRestore(p, snapshot); err != nil {
+		return fmt.Errorf("failed to plan restore: %w", err)
+	}
+
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Restored snapshot %s\n", snapshot.ID)
+	for _, entry := range changed {
+		if entry.Exists {
+			fmt.Printf("  Restored: %s\n", entry.Path)
+		} else {
+			fmt.Printf("  Removed:  %s\n", entry.Path)
+		}
+	}
+	fmt.Println("\nThe previous state was snapshotted too; undo with 'gh-switch backup list'.")
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/calghar/gh-account-switcher/internal/ssh"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cloneProfile string
+	cloneRoot    string
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <url|owner/repo>",
+	Short: "Clone a repository with the right profile",
+	Long: `Clone a repository through a profile's SSH host alias into that profile's
+directory, so the clone uses the profile's key and identity from the start.
+
+The profile is taken from --profile, from the host alias in the URL, or from
+the organization routed through a profile with 'gh-switch org add'. The
+repository is cloned into <root>/<owner>/<repo>, where root is --root, the
+profile's first directory rule, or ~/<profile>. If the directory rules don't
+already select the profile there, a rule for root is added.
+
+Examples:
+  gh-switch clone acme/api
+  gh-switch clone https://github.com/acme/api
+  gh-switch clone --profile personal me/dotfiles
+  gh-switch clone --profile work --root ~/src/work git@github.com:acme/api.git`,
+	Args: cobra.ExactArgs(1),
+	RunE: mutating(runClone),
+}
+
+func init() {
+	cloneCmd.Flags().StringVarP(&cloneProfile, "profile", "p", "", "Profile to clone with")
+	cloneCmd.Flags().StringVar(&cloneRoot, "root", "", "Directory to clone under (default: the profile's directory rule)")
+	rootCmd.AddCommand(cloneCmd)
+}
+
+// cloneTarget is a repository to clone, as given on the command line
+type cloneTarget struct {
+	// Host is the hostname, or "" for owner/repo shorthand
+	Host string
+	// Owner may contain slashes, for GitLab subgroups
+	Owner string
+	Repo  string
+	// AliasProfile is the profile whose host alias the URL goes through
+	AliasProfile string
+}
+
+func runClone(cmd *cobra.Command, args []string) error {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	target, err := parseCloneTarget(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	profile, err := cloneProfileFor(cfg, target)
+	if err != nil {
+		return err
+	}
+
+	host := profile.PrimaryHost()
+	if target.Host != "" {
+		var ok bool
+		if host, ok = profile.FindHost(target.Host); !ok {
+			return fmt.Errorf("profile '%s' has no host %s; add it with 'gh-switch host add %s %s'", profile.Name, target.Host, profile.Name, target.Host)
+		}
+	}
+	url := host.CloneURL(profile.Name, target.Owner+"/"+target.Repo)
+
+	root, err := cloneRootFor(cfg, profile)
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(root, filepath.FromSlash(target.Owner), target.Repo)
+	if entries, err := os.ReadDir(dest); err == nil && len(entries) > 0 {
+		return fmt.Errorf("destination already exists and is not empty: %s", dest)
+	}
+
+	p := plan.New()
+
+	// Register a rule for root unless the rules already select the profile
+	var rule *config.DirectoryRule
+	if selected, err := cfg.GetProfileForDirectory(dest); err != nil || selected != profile.Name {
+		if rule, err = planCloneRule(p, cfg, profile, root, dest); err != nil {
+			return err
+		}
+	}
+
+	// The alias only resolves with the profile's Host entry in ~/.ssh/config
+	sshMgr, err := ssh.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize SSH manager: %w", err)
+	}
+	if err := sshMgr.PlanEnsureProfileEntry(p, profile); err != nil {
+		return fmt.Errorf("failed to plan SSH config entry: %w", err)
+	}
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+
+	// Cloning comes last, so a failed clone rolls back the rule and SSH entry
+	p.Run([]string{"git", "clone", url, dest})
+
+	fmt.Printf("Cloning %s/%s with profile '%s'\n", target.Owner, target.Repo, profile.Name)
+	fmt.Printf("  URL: %s\n", url)
+	fmt.Printf("  Into: %s\n", dest)
+	if rule != nil {
+		fmt.Printf("  New directory rule: %s → %s\n", rule.Path, profile.Name)
+	}
+	fmt.Println()
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Cloned into %s\n", dest)
+	return nil
+}
+
+// parseCloneTarget parses a clone URL or owner/repo shorthand
+func parseCloneTarget(cfg *config.Config, arg string) (cloneTarget, error) {
+	var target cloneTarget
+	path := arg
+
+	// A host alias stands for the host it points at
+	if parsed, aliasProfile, ok := cfg.ResolveRemoteURL(arg); ok {
+		target.Host, path, target.AliasProfile = parsed.Host, parsed.Path, aliasProfile
+	} else if strings.Contains(arg, ":") {
+		return target, fmt.Errorf("unsupported repository URL: %s", arg)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	slash := strings.LastIndex(path, "/")
+	if slash <= 0 || slash == len(path)-1 {
+		return target, fmt.Errorf("expected a URL or owner/repo, got '%s'", arg)
+	}
+	target.Owner, target.Repo = path[:slash], path[slash+1:]
+
+	// Owners and names become directories
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return target, fmt.Errorf("invalid repository path: %s", path)
+		}
+	}
+	return target, nil
+}
+
+// cloneProfileFor picks the profile to clone with: the --profile flag, the
+// profile whose host alias the URL uses, or the profile the organization is
+// routed through
+func cloneProfileFor(cfg *config.Config, target cloneTarget) (*config.Profile, error) {
+	name := cloneProfile
+	if name == "" {
+		name = target.AliasProfile
+	}
+	if name == "" {
+		hostname := target.Host
+		if hostname == "" {
+			hostname = config.DefaultHostName
+		}
+
+		// Organizations are routed by their top-level owner
+		org, _, _ := strings.Cut(target.Owner, "/")
+		var ok bool
+		if name, ok = cfg.ProfileForOrg(hostname, org); !ok {
+			return nil, fmt.Errorf("no profile routes organization '%s' on %s; pass --profile or run 'gh-switch org add <profile> %s'", org, hostname, org)
+		}
+	}
+
+	profile, err := cfg.GetProfile(name)
+	if err != nil {
+		return nil, fmt.Errorf("profile not found: %w", err)
+	}
+	return profile, nil
+}
+
+// cloneRootFor returns the directory a profile's clones go under: --root,
+// the profile's first literal directory rule, or ~/<profile>
+func cloneRootFor(cfg *config.Config, profile *config.Profile) (string, error) {
+	if cloneRoot != "" {
+		if config.IsGlob(cloneRoot) {
+			return "", fmt.Errorf("--root must be a directory, not a pattern: %s", cloneRoot)
+		}
+		return config.ExpandPath(cloneRoot)
+	}
+
+	for _, rule := range cfg.DirectoryRules {
+		if rule.Profile == profile.Name && !config.IsGlob(rule.Path) {
+			return rule.Path, nil
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, profile.Name), nil
+}
+
+// planCloneRule adds a directory rule selecting profile under root and
+// schedules its includeIf directives
+func planCloneRule(p *plan.Plan, cfg *config.Config, profile *config.Profile, root, dest string) (*config.DirectoryRule, error) {
+	if existing, err := cfg.FindDirectoryRule(root); err == nil && existing.Profile != profile.Name {
+		return nil, fmt.Errorf("%s belongs to profile '%s'; choose another --root", root, existing.Profile)
+	}
+
+	rule, err := cfg.AddDirectoryRule(config.DirectoryRule{Path: root, Profile: profile.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add directory rule: %w", err)
+	}
+
+	// An exclusion or a more specific rule may still send dest elsewhere
+	if selected, err := cfg.GetProfileForDirectory(dest); err != nil || selected != profile.Name {
+		return nil, fmt.Errorf("directory rules would not select profile '%s' in %s; choose another --root", profile.Name, dest)
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+	if err := gitMgr.PlanSetupDirectoryRule(p, profile, *rule); err != nil {
+		return nil, fmt.Errorf("failed to plan Git includeIf: %w", err)
+	}
+	return rule, nil
+}
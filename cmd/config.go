@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var configAdd bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage extra git settings for a profile",
+	Long: `Manage extra git settings written into a profile's .gitconfig-<profile>
+file and applied to the global config on 'gh-switch switch'.
+
+Settings survive every regeneration of the profile file, so there is no need
+to hand-edit it. Keys may be multi-valued: use --add to append a value
+instead of replacing the existing ones.
+
+Examples:
+  gh-switch config set work pull.rebase true
+  gh-switch config set work core.hooksPath ~/work/hooks
+  gh-switch config set work url.git@github.com-work:.insteadOf https://github.com/
+  gh-switch config set work url.git@github.com-work:.insteadOf git@github.com: --add
+  gh-switch config unset work http.proxy
+  gh-switch config list work`,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <profile> <key> <value>",
+	Short: "Set a git setting for a profile",
+	Args:  cobra.ExactArgs(3),
+	RunE:  mutating(runConfigSet),
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <profile> <key> [value]",
+	Short: "Remove a git setting, or one of its values, from a profile",
+	Args:  cobra.RangeArgs(2, 3),
+	RunE:  mutating(runConfigUnset),
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list <profile>",
+	Short: "List a profile's extra git settings",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigList,
+}
+
+func init() {
+	configSetCmd.Flags().BoolVar(&configAdd, "add", false, "Add a value to a multi-valued key instead of replacing it")
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	profileName, key, value := args[0], args[1], args[2]
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	// A profile's hooks path would override the managed hooks in its repositories
+	if canonical, err := config.ValidateGitConfigKey(key); err == nil && canonical == "core.hookspath" {
+		gitMgr, err := git.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+		if installed, err := gitMgr.HooksInstalled(); err != nil {
+			return err
+		} else if installed {
+			return fmt.Errorf("core.hooksPath is managed by 'gh-switch hooks install'; put repository hooks in .git/hooks, which the managed hooks run after their checks")
+		}
+	}
+
+	if err := profile.SetGitConfig(key, value, configAdd); err != nil {
+		return err
+	}
+
+	if applied, err := saveProfileChange(cfg, configMgr, profile); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Set %s = %s for profile '%s'\n", key, value, profileName)
+	printSwitchHint(cfg, profileName)
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	profileName, key := args[0], args[1]
+	var value *string
+	if len(args) == 3 {
+		value = &args[2]
+	}
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	removed, err := profile.UnsetGitConfig(key, value)
+	if err != nil {
+		return err
+	}
+
+	if applied, err := saveProfileChange(cfg, configMgr, profile); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Removed %d value(s) of %s from profile '%s'\n", removed, key, profileName)
+	printSwitchHint(cfg, profileName)
+	return nil
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	if len(profile.GitConfig) == 0 {
+		fmt.Printf("No extra git settings for profile '%s'.\n", profileName)
+		fmt.Printf("Add one with: gh-switch config set %s <key> <value>\n", profileName)
+		return nil
+	}
+
+	for _, entry := range profile.SortedGitConfig() {
+		fmt.Printf("%s=%s\n", entry.Key, entry.Value)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/doctor"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/calghar/gh-account-switcher/internal/ssh"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose drift between config.json and Git/SSH configuration",
+	Long: `Check that the state derived from config.json is still intact: includeIf
+directives and the profile files they point at, SSH Host entries, SSH key
+permissions, GPG keys and directory rules.
+
+Findings are grouped by severity. With --fix, every repairable finding is
+fixed in a single all-or-nothing change (combine with --dry-run to preview).
+
+Examples:
+  gh-switch doctor
+  gh-switch doctor --fix
+  gh-switch --dry-run doctor --fix`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if doctorFix {
+			return mutating(runDoctor)(cmd, args)
+		}
+		return runDoctor(cmd, args)
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair fixable findings")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	sshMgr, err := ssh.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize SSH manager: %w", err)
+	}
+
+	env := &doctor.Env{Config: cfg, Git: gitMgr, SSH: sshMgr}
+	checks := doctor.DefaultChecks()
+	results := doctor.Run(env, checks)
+
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Printf("⚠ Check '%s' could not run: %v\n", result.Check, result.Err)
+		}
+	}
+
+	grouped := doctor.BySeverity(results)
+	total := 0
+	for _, severity := range []doctor.Severity{doctor.Error, doctor.Warning, doctor.Info} {
+		findings := grouped[severity]
+		if len(findings) == 0 {
+			continue
+		}
+		total += len(findings)
+
+		fmt.Printf("%s (%d):\n", severityTitle(severity), len(findings))
+		for _, finding := range findings {
+			fmt.Printf("  %s [%s] %s\n", severityMarker(severity), finding.Check, finding.Message)
+			if finding.Fixable() {
+				fmt.Printf("      fix: %s\n", finding.FixDescription)
+			}
+		}
+		fmt.Println()
+	}
+
+	if total == 0 {
+		fmt.Printf("✓ All %d checks passed\n", len(checks))
+		return nil
+	}
+
+	if !doctorFix {
+		fmt.Printf("%d finding(s) across %d checks.", total, len(checks))
+		if countFixable(results) > 0 {
+			fmt.Print(" Run 'gh-switch doctor --fix' to repair the fixable ones.")
+		}
+		fmt.Println()
+		if len(grouped[doctor.Error]) > 0 {
+			return fmt.Errorf("doctor found %d error(s)", len(grouped[doctor.Error]))
+		}
+		return nil
+	}
+
+	// Collect every fix into one plan, then persist any config.json changes
+	p := plan.New()
+	fixed := 0
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			if !finding.Fixable() {
+				continue
+			}
+			if err := finding.Fix(p); err != nil {
+				return fmt.Errorf("failed to plan fix for [%s] %s: %w", finding.Check, finding.Message, err)
+			}
+			fixed++
+		}
+	}
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Fixed %d of %d finding(s)\n", fixed, total)
+	if fixed < total {
+		fmt.Println("  The remaining findings need manual attention.")
+	}
+
+	return nil
+}
+
+// countFixable returns how many findings can be repaired automatically
+func countFixable(results []doctor.Result) int {
+	count := 0
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			if finding.Fixable() {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// severityTitle returns the heading for a group of findings
+func severityTitle(severity doctor.Severity) string {
+	switch severity {
+	case doctor.Error:
+		return "Errors"
+	case doctor.Warning:
+		return "Warnings"
+	default:
+		return "Info"
+	}
+}
+
+// severityMarker returns the symbol printed before a finding
+func severityMarker(severity doctor.Severity) string {
+	switch severity {
+	case doctor.Error:
+		return "✗"
+	case doctor.Warning:
+		return "⚠"
+	default:
+		return "ℹ"
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 	"github.com/spf13/cobra"
 )
 
@@ -11,14 +12,14 @@ var addEmailCmd = &cobra.Command{
 	Use:   "add-email <profile> <email>",
 	Short: "Add an email to a profile",
 	Args:  cobra.ExactArgs(2),
-	RunE:  runAddEmail,
+	RunE:  mutating(runAddEmail),
 }
 
 var removeEmailCmd = &cobra.Command{
 	Use:   "remove-email <profile> <email>",
 	Short: "Remove an email from a profile",
 	Args:  cobra.ExactArgs(2),
-	RunE:  runRemoveEmail,
+	RunE:  mutating(runRemoveEmail),
 }
 
 var listEmailsCmd = &cobra.Command{
@@ -52,8 +53,13 @@ func runAddEmail(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err := configMgr.Save(cfg); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
 	}
 
 	fmt.Printf("✓ Added email '%s' to profile '%s'\n", email, profileName)
@@ -78,8 +84,13 @@ func runRemoveEmail(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err := configMgr.Save(cfg); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
 	}
 
 	fmt.Printf("✓ Removed email '%s' from profile '%s'\n", email, profileName)
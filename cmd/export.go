@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 	"github.com/spf13/cobra"
 )
 
@@ -27,7 +28,7 @@ var importCmd = &cobra.Command{
 	Short: "Import profiles from a file",
 	Long:  `Import profiles from a JSON file.`,
 	Args:  cobra.ExactArgs(1),
-	RunE:  runImport,
+	RunE:  mutating(runImport),
 }
 
 func init() {
@@ -75,9 +76,9 @@ func runImport(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read import file: %w", err)
 	}
 
-	// Parse JSON
-	var importedCfg config.Config
-	if err := json.Unmarshal(data, &importedCfg); err != nil {
+	// Parse JSON, upgrading files exported by older releases
+	importedCfg, err := config.Decode(data)
+	if err != nil {
 		return fmt.Errorf("failed to parse import file: %w", err)
 	}
 
@@ -118,6 +119,15 @@ func runImport(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		// Keys may be imported into the keyring later, so problems are only reported
+		issues, err := checkSigningKey(profile)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			fmt.Printf("Warning: Profile '%s': %s\n", name, issue.Message)
+		}
+
 		cfg.Profiles[name] = profile
 	}
 
@@ -137,9 +147,20 @@ func runImport(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Save configuration
-	if err := configMgr.Save(cfg); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+	// Merge remote rules
+	for _, rule := range importedCfg.RemoteRules {
+		if err := cfg.AddRemoteRule(rule.Pattern, rule.Profile); err != nil {
+			return fmt.Errorf("failed to import remote rule %s: %w", rule.Pattern, err)
+		}
+	}
+
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
 	}
 
 	fmt.Printf("✓ Import completed!\n")
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/gpg"
+	"github.com/spf13/cobra"
+)
+
+var gpgCmd = &cobra.Command{
+	Use:   "gpg",
+	Short: "Inspect GPG signing keys",
+}
+
+var gpgListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List GPG secret keys and the profiles using them",
+	Long: `List the secret keys in your GPG keyring, which profiles sign with each
+of them, and any problem that would make signing fail: missing secret parts,
+expired or revoked keys, or user IDs that do not match the profile's emails.
+
+Examples:
+  gh-switch gpg list`,
+	Args: cobra.NoArgs,
+	RunE: runGPGList,
+}
+
+func init() {
+	gpgCmd.AddCommand(gpgListCmd)
+	rootCmd.AddCommand(gpgCmd)
+}
+
+func runGPGList(cmd *cobra.Command, args []string) error {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if !gpg.Available() {
+		return fmt.Errorf("gpg is not installed")
+	}
+
+	keys, err := gpg.ListSecretKeys()
+	if err != nil {
+		return err
+	}
+
+	// Map keys to the profiles signing with them
+	var names []string
+	for name, profile := range cfg.Profiles {
+		if profile.Signing != nil && profile.Signing.Format == config.SigningFormatOpenPGP {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	profilesByKey := make(map[*gpg.Key][]string)
+	var missing []string
+	for _, name := range names {
+		key, _ := gpg.Find(keys, cfg.Profiles[name].Signing.Key)
+		if key == nil {
+			missing = append(missing, name)
+			continue
+		}
+		profilesByKey[key] = append(profilesByKey[key], name)
+	}
+
+	if len(keys) == 0 {
+		fmt.Println("No GPG secret keys found.")
+	} else {
+		fmt.Printf("GPG secret keys (%d):\n", len(keys))
+	}
+
+	now := time.Now()
+	for _, key := range keys {
+		fmt.Printf("\n  %s", key.KeyID)
+		if len(key.UIDs) > 0 {
+			fmt.Printf("  %s", formatUID(key.UIDs[0]))
+		}
+		fmt.Println()
+		for _, uid := range key.UIDs[min(1, len(key.UIDs)):] {
+			fmt.Printf("    Also: %s\n", formatUID(uid))
+		}
+		fmt.Printf("    Fingerprint: %s\n", key.Fingerprint)
+
+		switch {
+		case key.Revoked():
+			fmt.Printf("    Status: revoked\n")
+		case key.Expired(now):
+			fmt.Printf("    Status: expired %s\n", key.Expires.Format("2006-01-02"))
+		case !key.Expires.IsZero():
+			fmt.Printf("    Expires: %s\n", key.Expires.Format("2006-01-02"))
+		}
+
+		profiles := profilesByKey[key]
+		if len(profiles) == 0 {
+			continue
+		}
+		fmt.Printf("    Profiles: %s\n", strings.Join(profiles, ", "))
+		for _, name := range profiles {
+			profile := cfg.Profiles[name]
+			for _, issue := range gpg.CheckSigningKey(keys, profile.Signing.Key, profile.Emails, now) {
+				fmt.Printf("    %s %s: %s\n", issueMarker(issue), name, issue.Message)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		fmt.Println("\nProfiles whose signing key is not in the keyring:")
+		for _, name := range missing {
+			fmt.Printf("  ✗ %s: %s\n", name, cfg.Profiles[name].Signing.Key)
+		}
+	}
+
+	return nil
+}
+
+// formatUID renders a user ID as "Name <email>"
+func formatUID(uid gpg.UID) string {
+	if uid.Email == "" {
+		return uid.Name
+	}
+	if uid.Name == "" {
+		return "<" + uid.Email + ">"
+	}
+	return fmt.Sprintf("%s <%s>", uid.Name, uid.Email)
+}
+
+// issueMarker returns the marker printed before a signing key issue
+func issueMarker(issue gpg.Issue) string {
+	if issue.Blocking {
+		return "✗"
+	}
+	return "⚠"
+}
+
+// checkSigningKey verifies a profile's OpenPGP signing key against the local
+// keyring. Profiles signing with other formats have nothing to check.
+func checkSigningKey(profile *config.Profile) ([]gpg.Issue, error) {
+	if profile.Signing == nil || profile.Signing.Format != config.SigningFormatOpenPGP {
+		return nil, nil
+	}
+
+	if !gpg.Available() {
+		return []gpg.Issue{{Message: "gpg is not installed; the GPG signing key cannot be verified"}}, nil
+	}
+
+	keys, err := gpg.ListSecretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	return gpg.CheckSigningKey(keys, profile.Signing.Key, profile.Emails, time.Now()), nil
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/hooks"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Enforce profile identities with Git hooks",
+	Long: `Install a global Git hooks directory that blocks commits and pushes made
+with an identity other than the profile the rules select.
+
+'gh-switch hooks install' writes hooks to ~/.github-switcher/hooks and points
+the global core.hooksPath at it. The pre-commit hook compares the author and
+committer email Git would record with the emails of the profile the directory
+and remote rules select, and aborts the commit on a mismatch.
+
+The pre-push hook blocks pushes to repositories whose owner is not on the
+profile's allow-list (see 'gh-switch push'), through another profile's SSH host
+alias, or that send commits with an email outside the profile.
+
+Every hook then runs the repository's own hook of the same name from
+.git/hooks, so existing hooks keep working.
+
+Repositories that set core.hooksPath locally (e.g. husky) bypass the global
+hooks directory.
+
+Examples:
+  gh-switch hooks install
+  gh-switch hooks uninstall`,
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the managed hooks and set the global core.hooksPath",
+	Args:  cobra.NoArgs,
+	RunE:  mutating(runHooksInstall),
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the managed hooks and the global core.hooksPath",
+	Args:  cobra.NoArgs,
+	RunE:  mutating(runHooksUninstall),
+}
+
+// hooksRunCmd is what the installed hook scripts call
+var hooksRunCmd = &cobra.Command{
+	Use:                "run <hook> [args...]",
+	Short:              "Run gh-switch's check for a Git hook",
+	Args:               cobra.MinimumNArgs(1),
+	Hidden:             true,
+	DisableFlagParsing: true,
+	SilenceUsage:       true,
+	SilenceErrors:      true,
+	RunE:               runHooksRun,
+}
+
+func init() {
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+	hooksCmd.AddCommand(hooksRunCmd)
+	rootCmd.AddCommand(hooksCmd)
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	hooksMgr, err := hooks.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize hooks manager: %w", err)
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	// Hooks in another global directory would silently stop running
+	if existing, ok, err := gitMgr.HooksPath(); err != nil {
+		return err
+	} else if ok && !git.OwnsHooksPath(existing) {
+		return fmt.Errorf("core.hooksPath is already set to %s; move those hooks into the repositories' .git/hooks and run 'git config --global --unset core.hooksPath' first", existing)
+	}
+
+	// A profile's own hooks path would override the global one in its
+	// repositories, bypassing the checks
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	for _, profile := range cfg.Profiles {
+		if values := profile.GitConfigValues("core.hooksPath"); len(values) > 0 {
+			return fmt.Errorf("profile '%s' sets core.hooksPath to %s, which would bypass the hooks; move those hooks into the repositories' .git/hooks and run 'gh-switch config unset %s core.hooksPath' first", profile.Name, values[0], profile.Name)
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate gh-switch executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	p := plan.New()
+	if err := hooksMgr.PlanInstall(p, executable); err != nil {
+		return err
+	}
+	if err := gitMgr.PlanSetHooksPath(p, hooksMgr.Dir()); err != nil {
+		return fmt.Errorf("failed to plan core.hooksPath: %w", err)
+	}
+
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Installed hooks in %s\n", hooksMgr.Dir())
+	fmt.Println("✓ Set global core.hooksPath")
+	fmt.Println("  Commits and pushes outside the selected profile are now blocked.")
+	fmt.Println("  Repository hooks in .git/hooks still run after gh-switch's checks.")
+	return nil
+}
+
+func runHooksUninstall(cmd *cobra.Command, args []string) error {
+	hooksMgr, err := hooks.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize hooks manager: %w", err)
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	p := plan.New()
+	if err := hooksMgr.PlanUninstall(p); err != nil {
+		return err
+	}
+
+	// A hooks path the user set to another directory is theirs
+	existing, ok, err := gitMgr.HooksPath()
+	if err != nil {
+		return err
+	}
+	owned := ok && git.OwnsHooksPath(existing)
+	if owned {
+		if err := gitMgr.PlanUnsetHooksPath(p); err != nil {
+			return fmt.Errorf("failed to plan core.hooksPath removal: %w", err)
+		}
+	}
+
+	if p.Empty() {
+		fmt.Println("✓ Hooks are not installed")
+		return nil
+	}
+
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Removed hooks from %s\n", hooksMgr.Dir())
+	if owned {
+		fmt.Println("✓ Unset global core.hooksPath")
+	} else if ok {
+		fmt.Printf("⚠ core.hooksPath points at %s and was left unchanged\n", existing)
+	}
+	return nil
+}
+
+func runHooksRun(cmd *cobra.Command, args []string) error {
+	if !hooks.IsChecked(args[0]) {
+		return nil
+	}
+	if args[0] == "pre-push" && len(args) < 3 {
+		return fmt.Errorf("pre-push hook expects the remote name and URL")
+	}
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Git runs hooks from the top of the working tree
+	if args[0] == "pre-push" {
+		return hooks.CheckPush(cfg, ".", args[2], os.Stdin)
+	}
+	return hooks.CheckCommit(cfg, ".")
+}
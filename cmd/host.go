@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/calghar/gh-account-switcher/internal/ssh"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hostForge   string
+	hostPort    int
+	hostSSHUser string
+	hostAlias   string
+)
+
+var hostCmd = &cobra.Command{
+	Use:   "host",
+	Short: "Manage the git hosts a profile uses",
+	Long: `A profile authenticates against one or more git hosts: github.com, GitHub
+Enterprise Server, GitLab, Bitbucket or Gitea. Each host gets its own SSH
+host alias, generated from an alias template ({host}-{profile} by default).
+
+Examples:
+  gh-switch host add work github.example.com
+  gh-switch host add work gitlab.example.com --port 2222 --alias gitlab-{profile}
+  gh-switch host remove work github.com
+  gh-switch host list work`,
+}
+
+var hostAddCmd = &cobra.Command{
+	Use:   "add <profile> <hostname>",
+	Short: "Add a host to a profile, or update its settings",
+	Args:  cobra.ExactArgs(2),
+	RunE:  mutating(runHostAdd),
+}
+
+var hostRemoveCmd = &cobra.Command{
+	Use:   "remove <profile> <hostname>",
+	Short: "Remove a host from a profile",
+	Args:  cobra.ExactArgs(2),
+	RunE:  mutating(runHostRemove),
+}
+
+var hostListCmd = &cobra.Command{
+	Use:   "list [profile]",
+	Short: "List profile hosts and their SSH aliases",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runHostList,
+}
+
+func init() {
+	addHostFlags(hostAddCmd)
+	hostCmd.AddCommand(hostAddCmd)
+	hostCmd.AddCommand(hostRemoveCmd)
+	hostCmd.AddCommand(hostListCmd)
+	rootCmd.AddCommand(hostCmd)
+}
+
+// addHostFlags registers the per-host settings on a command
+func addHostFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&hostForge, "forge", "", "Forge type: github, gitlab, bitbucket or gitea (detected from the hostname by default)")
+	cmd.Flags().IntVar(&hostPort, "port", 0, "SSH port (default 22)")
+	cmd.Flags().StringVar(&hostSSHUser, "ssh-user", "", "SSH user (default git)")
+	cmd.Flags().StringVar(&hostAlias, "alias", "", "SSH host alias template using {profile}, {host} and {forge} (default {host}-{profile})")
+}
+
+// hostFromFlags builds a host from a hostname and the per-host flags
+func hostFromFlags(hostname string) config.Host {
+	return config.Host{
+		HostName: hostname,
+		Forge:    hostForge,
+		Port:     hostPort,
+		User:     hostSSHUser,
+		Alias:    hostAlias,
+	}
+}
+
+func runHostAdd(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+	host := hostFromFlags(args[1])
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	if err := profile.AddHost(host); err != nil {
+		return err
+	}
+	if err := cfg.AddProfile(profile); err != nil {
+		return err
+	}
+
+	sshMgr, err := ssh.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize SSH manager: %w", err)
+	}
+
+	p := plan.New()
+	if err := sshMgr.PlanEnsureProfileEntry(p, profile); err != nil {
+		return fmt.Errorf("failed to plan SSH config: %w", err)
+	}
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+	// Organization URL rewrites depend on the profile's hosts
+	if err := planRefreshProfileConfig(p, profile); err != nil {
+		return err
+	}
+
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Host '%s' (%s) set for profile '%s'\n", host.HostName, host.ForgeName(), profileName)
+	fmt.Printf("  Use this host in git URLs: %s\n", host.CloneURL(profileName, "owner/repo"))
+	fmt.Printf("  Register your public key at: %s\n", host.SSHKeysURL())
+	return nil
+}
+
+func runHostRemove(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+	hostname := args[1]
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	if err := profile.RemoveHost(hostname); err != nil {
+		return err
+	}
+
+	sshMgr, err := ssh.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize SSH manager: %w", err)
+	}
+
+	p := plan.New()
+	if err := sshMgr.PlanEnsureProfileEntry(p, profile); err != nil {
+		return fmt.Errorf("failed to plan SSH config: %w", err)
+	}
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+	// Organization URL rewrites depend on the profile's hosts
+	if err := planRefreshProfileConfig(p, profile); err != nil {
+		return err
+	}
+
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Removed host '%s' from profile '%s'\n", hostname, profileName)
+	return nil
+}
+
+func runHostList(cmd *cobra.Command, args []string) error {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var names []string
+	if len(args) == 1 {
+		if _, err := cfg.GetProfile(args[0]); err != nil {
+			return err
+		}
+		names = args
+	} else {
+		for name := range cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No profiles configured.")
+		return nil
+	}
+
+	for _, name := range names {
+		profile := cfg.Profiles[name]
+		fmt.Printf("%s:\n", name)
+		for _, host := range profile.HostList() {
+			fmt.Printf("  %s (%s)\n", host.HostName, host.ForgeName())
+			fmt.Printf("    SSH alias: %s\n", host.HostAlias(name))
+			if host.Port != 0 {
+				fmt.Printf("    Port: %d\n", host.Port)
+			}
+			fmt.Printf("    Clone URL: %s\n", host.CloneURL(name, "owner/repo"))
+		}
+	}
+
+	return nil
+}
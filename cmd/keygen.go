@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/calghar/gh-account-switcher/internal/ssh"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	keygenForce        bool
+	keygenNoPassphrase bool
+)
+
+var keygenCmd = &cobra.Command{
+	Use:   "keygen <profile>",
+	Short: "Generate an SSH key for a profile",
+	Long: `Generate an Ed25519 SSH key pair for a profile at its key path
+(~/.ssh/id_<profile> by default), commented with the profile's primary email.
+
+You are prompted for an optional passphrase when running in a terminal.
+The profile's SSH key path and SSH config entry are updated to use the key.
+
+Examples:
+  gh-switch keygen work
+  gh-switch keygen work --force
+  gh-switch keygen work --no-passphrase`,
+	Args: cobra.ExactArgs(1),
+	RunE: mutating(runKeygen),
+}
+
+func init() {
+	keygenCmd.Flags().BoolVar(&keygenForce, "force", false, "Overwrite an existing key pair")
+	keygenCmd.Flags().BoolVar(&keygenNoPassphrase, "no-passphrase", false, "Do not prompt for a passphrase")
+	rootCmd.AddCommand(keygenCmd)
+}
+
+func runKeygen(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	sshMgr, err := ssh.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize SSH manager: %w", err)
+	}
+
+	// Planning the SSH entry also fills in the default key path
+	p := plan.New()
+	if err := sshMgr.PlanEnsureProfileEntry(p, profile); err != nil {
+		return fmt.Errorf("failed to plan SSH config: %w", err)
+	}
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+
+	keyPath := profile.SSHKeyPath
+	if !keygenForce && (ssh.CheckSSHKeyExists(keyPath) || ssh.CheckSSHKeyExists(keyPath+".pub")) {
+		return fmt.Errorf("SSH key already exists: %s (use --force to overwrite)", keyPath)
+	}
+
+	if dryRun {
+		fmt.Printf("Would generate an Ed25519 key pair at %s\n\n", keyPath)
+		_, err := applyPlan(p)
+		return err
+	}
+
+	var passphrase []byte
+	if !keygenNoPassphrase && !skipPrompts && term.IsTerminal(int(os.Stdin.Fd())) {
+		passphrase, err = readPassphrase()
+		if err != nil {
+			return err
+		}
+	}
+
+	key, err := ssh.GenerateKey(keyPath, profile.PrimaryEmail, passphrase, keygenForce)
+	if err != nil {
+		return fmt.Errorf("failed to generate SSH key: %w", err)
+	}
+
+	// Installing the pair is the plan's last step, so a failure anywhere puts
+	// back the previous pair along with the SSH config and profile
+	defer key.Discard()
+	p.Add(key)
+	if _, err := applyPlan(p); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Generated SSH key for profile '%s'\n", profileName)
+	fmt.Printf("  Private key: %s\n", key.PrivateKeyPath)
+	fmt.Printf("  Public key: %s\n", key.PublicKeyPath)
+	fmt.Printf("  Fingerprint: %s\n", key.Fingerprint)
+
+	fmt.Println("\nRegister the public key with each host:")
+	for _, host := range profile.HostList() {
+		fmt.Printf("  %s: %s\n", host.HostName, host.SSHKeysURL())
+	}
+
+	return nil
+}
+
+// readPassphrase prompts twice for a passphrase without echoing it
+func readPassphrase() ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+
+	fmt.Print("Enter passphrase (empty for no passphrase): ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if len(passphrase) == 0 {
+		return nil, nil
+	}
+
+	fmt.Print("Enter same passphrase again: ")
+	confirmation, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if !bytes.Equal(passphrase, confirmation) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+
+	return passphrase, nil
+}
@@ -73,12 +73,24 @@ func runList(cmd *cobra.Command, args []string) error {
 			fmt.Printf("    Git name: %s\n", profile.GitName)
 		}
 
-		if profile.GPGKey != "" {
-			fmt.Printf("    GPG key: %s\n", profile.GPGKey)
+		if profile.Signing != nil {
+			fmt.Printf("    Signing: %s key %s%s\n", profile.Signing.Format, profile.Signing.Key, signingScope(profile.Signing.Commits, profile.Signing.Tags))
+		}
+
+		for _, host := range profile.HostList() {
+			fmt.Printf("    Host: %s (%s) via %s\n", host.HostName, host.ForgeName(), host.HostAlias(name))
+		}
+
+		if len(profile.Orgs) > 0 {
+			fmt.Printf("    Orgs: %s\n", strings.Join(profile.Orgs, ", "))
+		}
+
+		if len(profile.PushOwners) > 0 {
+			fmt.Printf("    Push owners: %s\n", strings.Join(profile.PushOwners, ", "))
 		}
 
 		// Check SSH key status
-		sshKeyPath := ssh.GetSSHKeyPath(name)
+		sshKeyPath := ssh.KeyPath(profile)
 		if ssh.CheckSSHKeyExists(sshKeyPath) {
 			fmt.Printf("    SSH key: %s ✓\n", sshKeyPath)
 		} else {
@@ -92,7 +104,21 @@ func runList(cmd *cobra.Command, args []string) error {
 	if len(cfg.DirectoryRules) > 0 {
 		fmt.Println("Directory rules:")
 		for _, rule := range cfg.DirectoryRules {
-			fmt.Printf("  %s → %s\n", rule.Path, rule.Profile)
+			fmt.Printf("  %s → %s", rule.Path, rule.Profile)
+			if rule.IgnoreCase {
+				fmt.Print(" (case-insensitive)")
+			}
+			fmt.Println()
+			for _, exclude := range rule.Exclude {
+				fmt.Printf("    except %s\n", exclude)
+			}
+		}
+	}
+
+	if len(cfg.RemoteRules) > 0 {
+		fmt.Println("Remote rules:")
+		for _, rule := range cfg.RemoteRules {
+			fmt.Printf("  %s → %s\n", rule.Pattern, rule.Profile)
 		}
 	}
 
@@ -116,17 +142,21 @@ func runCurrent(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize git manager: %w", err)
 	}
 
-	email, name, signingKey, gpgEnabled := gitMgr.GetCurrentConfig()
+	identity, err := gitMgr.GetCurrentConfig()
+	if err != nil {
+		return fmt.Errorf("failed to read Git configuration: %w", err)
+	}
+	email := identity.Email
 
 	fmt.Println("Current Git configuration:")
 	fmt.Printf("  Email: %s\n", email)
-	if name != "" {
-		fmt.Printf("  Name: %s\n", name)
+	if identity.Name != "" {
+		fmt.Printf("  Name: %s\n", identity.Name)
 	}
-	if gpgEnabled && signingKey != "" {
-		fmt.Printf("  GPG signing: enabled (key: %s)\n", signingKey)
+	if (identity.SignCommits || identity.SignTags) && identity.SigningKey != "" {
+		fmt.Printf("  Signing: %s (key: %s)%s\n", identity.SigningFormat, identity.SigningKey, signingScope(identity.SignCommits, identity.SignTags))
 	} else {
-		fmt.Printf("  GPG signing: disabled\n")
+		fmt.Printf("  Signing: disabled\n")
 	}
 
 	// Try to find matching profile
@@ -153,3 +183,17 @@ func runCurrent(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// signingScope describes what a signing setup signs
+func signingScope(commits, tags bool) string {
+	switch {
+	case commits && tags:
+		return ", commits and tags"
+	case tags:
+		return ", tags only"
+	case commits:
+		return ", commits"
+	default:
+		return ", nothing signed"
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var orgHTTPS string
+
+var orgCmd = &cobra.Command{
+	Use:   "org",
+	Short: "Route organizations' repositories through a profile",
+	Long: `Route the repositories of organizations or owners through a profile's SSH
+host alias, so plain URLs copied from the web UI use the right key.
+
+For each organization, the profile's generated git config carries
+url.<alias>.insteadOf rules rewriting git@github.com:<org>/,
+ssh://git@github.com/<org>/ and https://github.com/<org>/ to
+git@github.com-<profile>:<org>/. The rules apply wherever the profile is
+active: in its rule directories, and globally after 'gh-switch switch'.
+
+HTTPS URLs are rewritten for fetch and push by default. With --https push,
+fetches keep using HTTPS and only pushes go through SSH (pushInsteadOf);
+--https none leaves HTTPS URLs alone.
+
+Matching is case-sensitive, as in git: add the organization as it appears in
+URLs.
+
+Examples:
+  gh-switch org add work work-org
+  gh-switch org add work work-org other-org --https push
+  gh-switch org remove work other-org
+  gh-switch org list`,
+}
+
+var orgAddCmd = &cobra.Command{
+	Use:   "add <profile> <org>...",
+	Short: "Route organizations through a profile",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  mutating(runOrgAdd),
+}
+
+var orgRemoveCmd = &cobra.Command{
+	Use:   "remove <profile> <org>...",
+	Short: "Stop routing organizations through a profile",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  mutating(runOrgRemove),
+}
+
+var orgListCmd = &cobra.Command{
+	Use:   "list [profile]",
+	Short: "List the organizations routed through each profile",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runOrgList,
+}
+
+func init() {
+	orgAddCmd.Flags().StringVar(&orgHTTPS, "https", "", "How HTTPS URLs are rewritten: all, push or none (default all)")
+	orgCmd.AddCommand(orgAddCmd)
+	orgCmd.AddCommand(orgRemoveCmd)
+	orgCmd.AddCommand(orgListCmd)
+	rootCmd.AddCommand(orgCmd)
+}
+
+func runOrgAdd(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+
+	if err := config.ValidateHTTPSRewrite(orgHTTPS); err != nil {
+		return err
+	}
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	before := len(profile.Orgs)
+	for _, org := range args[1:] {
+		if err := cfg.AddOrg(profileName, org); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("https") {
+		profile.HTTPSRewrite = orgHTTPS
+	}
+
+	if applied, err := saveProfileChange(cfg, configMgr, profile); err != nil || !applied {
+		return err
+	}
+
+	for _, org := range profile.Orgs[before:] {
+		fmt.Printf("✓ Routing '%s' through profile '%s'\n", org, profileName)
+		for _, host := range profile.HostList() {
+			fmt.Printf("  %s@%s:%s/ -> %s@%s:%s/\n", host.SSHUser(), host.HostName, org, host.SSHUser(), host.HostAlias(profileName), org)
+		}
+	}
+	printSwitchHint(cfg, profileName)
+	return nil
+}
+
+func runOrgRemove(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	for _, org := range args[1:] {
+		if err := cfg.RemoveOrg(profileName, org); err != nil {
+			return err
+		}
+	}
+
+	if applied, err := saveProfileChange(cfg, configMgr, profile); err != nil || !applied {
+		return err
+	}
+
+	for _, org := range args[1:] {
+		fmt.Printf("✓ Stopped routing '%s' through profile '%s'\n", org, profileName)
+	}
+	printSwitchHint(cfg, profileName)
+	return nil
+}
+
+func runOrgList(cmd *cobra.Command, args []string) error {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var names []string
+	if len(args) == 1 {
+		if _, err := cfg.GetProfile(args[0]); err != nil {
+			return err
+		}
+		names = args
+	} else {
+		for name, profile := range cfg.Profiles {
+			if len(profile.Orgs) > 0 {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No organizations are routed through a profile.")
+		fmt.Println("Add one with: gh-switch org add <profile> <org>")
+		return nil
+	}
+
+	for _, name := range names {
+		profile := cfg.Profiles[name]
+		https := profile.HTTPSRewrite
+		if https == "" {
+			https = config.HTTPSRewriteAll
+		}
+		fmt.Printf("%s (https: %s):\n", name, https)
+		if len(profile.Orgs) == 0 {
+			fmt.Println("  (none)")
+		}
+		orgs := append([]string(nil), profile.Orgs...)
+		sort.Strings(orgs)
+		for _, org := range orgs {
+			fmt.Printf("  %s\n", org)
+		}
+	}
+
+	return nil
+}
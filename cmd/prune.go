@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove orphaned includeIf directives",
+	Long: `Find includeIf directives that gh-switch created in your global .gitconfig
+but that no directory rule in config.json accounts for, and delete them.
+
+User-authored includeIf directives are never touched.
+
+Examples:
+  gh-switch prune
+  gh-switch --dry-run prune`,
+	Args: cobra.NoArgs,
+	RunE: mutating(runPrune),
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	orphans, err := gitMgr.OrphanIncludeIfs(cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) == 0 {
+		fmt.Println("✓ No orphaned includeIf directives found.")
+		return nil
+	}
+
+	p := plan.New()
+	for _, include := range orphans {
+		if err := gitMgr.PlanRemoveIncludeIf(p, include); err != nil {
+			return fmt.Errorf("failed to plan includeIf removal: %w", err)
+		}
+	}
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Removed %d orphaned includeIf directive(s):\n", len(orphans))
+	for _, include := range orphans {
+		fmt.Printf("  %s → %s\n", include.Condition, include.Path)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Control which owners' repositories a profile may push to",
+	Long: `Maintain each profile's push allow-list: the users and organizations whose
+repositories the pre-push hook installed by 'gh-switch hooks install' lets the
+profile push to.
+
+Organizations routed through the profile with 'gh-switch org add' are always
+allowed. A profile with neither organizations nor push owners may push
+anywhere; once it has one, pushes to any other owner are blocked, such as a
+work branch pushed to a personal fork. Owners are compared
+case-insensitively, as on GitHub.
+
+Examples:
+  gh-switch push allow work acme-labs my-work-user
+  gh-switch push disallow work acme-labs
+  gh-switch push list`,
+}
+
+var pushAllowCmd = &cobra.Command{
+	Use:   "allow <profile> <owner>...",
+	Short: "Allow a profile to push to owners' repositories",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  mutating(runPushAllow),
+}
+
+var pushDisallowCmd = &cobra.Command{
+	Use:   "disallow <profile> <owner>...",
+	Short: "Remove owners from a profile's push allow-list",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  mutating(runPushDisallow),
+}
+
+var pushListCmd = &cobra.Command{
+	Use:   "list [profile]",
+	Short: "List the owners each profile may push to",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runPushList,
+}
+
+func init() {
+	pushCmd.AddCommand(pushAllowCmd)
+	pushCmd.AddCommand(pushDisallowCmd)
+	pushCmd.AddCommand(pushListCmd)
+	rootCmd.AddCommand(pushCmd)
+}
+
+func runPushAllow(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	before := len(profile.PushOwners)
+	for _, owner := range args[1:] {
+		if err := cfg.AddPushOwner(profileName, owner); err != nil {
+			return err
+		}
+	}
+
+	if applied, err := saveProfileChange(cfg, configMgr, profile); err != nil || !applied {
+		return err
+	}
+
+	for _, owner := range profile.PushOwners[before:] {
+		fmt.Printf("✓ Profile '%s' may push to '%s'\n", profileName, owner)
+	}
+	return nil
+}
+
+func runPushDisallow(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	for _, owner := range args[1:] {
+		if err := cfg.RemovePushOwner(profileName, owner); err != nil {
+			return err
+		}
+	}
+
+	if applied, err := saveProfileChange(cfg, configMgr, profile); err != nil || !applied {
+		return err
+	}
+
+	for _, owner := range args[1:] {
+		fmt.Printf("✓ Profile '%s' may no longer push to '%s'\n", profileName, owner)
+	}
+	if len(profile.PushAllowList()) == 0 {
+		fmt.Printf("  Profile '%s' has no push allow-list left and may push anywhere.\n", profileName)
+	}
+	return nil
+}
+
+func runPushList(cmd *cobra.Command, args []string) error {
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var names []string
+	if len(args) == 1 {
+		if _, err := cfg.GetProfile(args[0]); err != nil {
+			return err
+		}
+		names = args
+	} else {
+		for name := range cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No profiles configured.")
+		return nil
+	}
+
+	for _, name := range names {
+		profile := cfg.Profiles[name]
+		fmt.Printf("%s:\n", name)
+		if len(profile.PushAllowList()) == 0 {
+			fmt.Println("  (any owner)")
+			continue
+		}
+
+		orgs := append([]string(nil), profile.Orgs...)
+		sort.Strings(orgs)
+		for _, org := range orgs {
+			fmt.Printf("  %s (routed organization)\n", org)
+		}
+		owners := append([]string(nil), profile.PushOwners...)
+		sort.Strings(owners)
+		for _, owner := range owners {
+			fmt.Printf("  %s\n", owner)
+		}
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/calghar/gh-account-switcher/internal/config"
 	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 	"github.com/calghar/gh-account-switcher/internal/ssh"
 	"github.com/spf13/cobra"
 )
@@ -17,7 +18,7 @@ var removeCmd = &cobra.Command{
 	Short: "Remove a profile",
 	Long:  `Remove a profile and its associated directory rules.`,
 	Args:  cobra.ExactArgs(1),
-	RunE:  runRemove,
+	RunE:  mutating(runRemove),
 }
 
 func init() {
@@ -46,7 +47,7 @@ func runRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	// Confirm removal if not skipping prompts
-	if !skipPrompts {
+	if !skipPrompts && !dryRun {
 		fmt.Printf("Are you sure you want to remove profile '%s'?\n", profileName)
 		fmt.Printf("  Email: %s\n", profile.PrimaryEmail)
 
@@ -57,8 +58,13 @@ func runRemove(cmd *cobra.Command, args []string) error {
 				ruleCount++
 			}
 		}
+		for _, rule := range cfg.RemoteRules {
+			if rule.Profile == profileName {
+				ruleCount++
+			}
+		}
 		if ruleCount > 0 {
-			fmt.Printf("  Associated directory rules: %d\n", ruleCount)
+			fmt.Printf("  Associated directory and remote rules: %d\n", ruleCount)
 		}
 
 		fmt.Print("\nType 'yes' to confirm: ")
@@ -77,29 +83,34 @@ func runRemove(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to remove profile: %w", err)
 	}
 
-	// Save configuration
-	if err := configMgr.Save(cfg); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
-	}
-
-	// Remove Git configuration
 	gitMgr, err := git.NewConfigManager()
-	if err == nil {
-		if err := gitMgr.RemoveProfileConfig(profileName); err != nil {
-			fmt.Printf("Warning: Failed to remove Git config: %v\n", err)
-		}
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
 	}
 
-	// Remove SSH configuration
 	sshMgr, err := ssh.NewConfigManager()
-	if err == nil {
-		if err := sshMgr.RemoveProfileEntry(profileName); err != nil {
-			fmt.Printf("Warning: Failed to remove SSH config: %v\n", err)
-		}
+	if err != nil {
+		return fmt.Errorf("failed to initialize SSH manager: %w", err)
+	}
+
+	// Remove configuration, Git config and SSH entry all-or-nothing
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+	if err := gitMgr.PlanRemoveProfileConfig(p, profileName); err != nil {
+		return fmt.Errorf("failed to plan Git config removal: %w", err)
+	}
+	if err := sshMgr.PlanRemoveProfileEntry(p, profileName); err != nil {
+		return fmt.Errorf("failed to plan SSH config removal: %w", err)
+	}
+
+	if applied, err := applyRulesPlan(p, cfg); err != nil || !applied {
+		return err
 	}
 
 	fmt.Printf("✓ Profile '%s' removed successfully\n", profileName)
-	fmt.Println("\nNote: SSH key file and Git includeIf directives were removed.")
+	fmt.Println("\nIts directory and remote rules, Git includeIf directives, profile gitconfig and SSH entry were removed.")
 	fmt.Println("Your SSH key file (if it exists) was not deleted.")
 
 	return nil
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var repoFixAllRemotes bool
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Manage existing repositories",
+}
+
+var repoFixCmd = &cobra.Command{
+	Use:   "fix [path]",
+	Short: "Point existing clones at their profile's SSH host alias",
+	Long: `Find the Git repositories under path (default: the current directory) and
+rewrite their remotes to go through the SSH host alias of the profile the
+directory rules select, so pushes use that profile's key.
+
+SSH and HTTPS URLs for one of the profile's hosts are rewritten, e.g.
+git@github.com:acme/api.git and https://github.com/acme/api become
+git@github.com-work:acme/api.git. Only origin is rewritten unless
+--all-remotes is given. Repositories no directory rule covers are skipped.
+
+Examples:
+  gh-switch repo fix ~/work
+  gh-switch --dry-run repo fix ~/work --all-remotes`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: mutating(runRepoFix),
+}
+
+func init() {
+	repoFixCmd.Flags().BoolVar(&repoFixAllRemotes, "all-remotes", false, "Rewrite every remote, not just origin")
+	repoCmd.AddCommand(repoFixCmd)
+	rootCmd.AddCommand(repoCmd)
+}
+
+func runRepoFix(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	if _, err := os.Stat(absRoot); err != nil {
+		return fmt.Errorf("path not found: %s", absRoot)
+	}
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	repos, err := git.FindRepositories(absRoot)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		fmt.Printf("No Git repositories found under %s\n", absRoot)
+		return nil
+	}
+
+	p := plan.New()
+	rewritten, changedRepos := 0, 0
+	for _, repo := range repos {
+		count, err := planRepoFix(p, cfg, repo)
+		if err != nil {
+			fmt.Printf("%s\n  ⚠ skipped: %v\n", repo, err)
+			continue
+		}
+		if count > 0 {
+			rewritten += count
+			changedRepos++
+		}
+	}
+
+	if rewritten == 0 {
+		fmt.Printf("\n✓ Checked %d repositories; no remotes need rewriting\n", len(repos))
+		return nil
+	}
+
+	fmt.Println()
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Rewrote %d remote(s) in %d of %d repositories\n", rewritten, changedRepos, len(repos))
+	return nil
+}
+
+// planRepoFix prints the before/after report for one repository and
+// schedules its remote rewrites, returning how many remotes change
+func planRepoFix(p *plan.Plan, cfg *config.Config, repo string) (int, error) {
+	gitDir, err := git.RepoGitDir(repo)
+	if err != nil {
+		return 0, err
+	}
+
+	profileName, err := cfg.GetProfileForGitDir(gitDir)
+	if err != nil {
+		fmt.Printf("%s\n  skipped: no directory rule\n", repo)
+		return 0, nil
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return 0, err
+	}
+
+	remotes, err := git.Remotes(repo)
+	if err != nil {
+		return 0, err
+	}
+
+	fmt.Printf("%s (%s)\n", repo, profileName)
+	count, considered := 0, 0
+	for _, remote := range remotes {
+		if remote.Name != "origin" && !repoFixAllRemotes {
+			continue
+		}
+		considered++
+
+		if aliased, ok := profile.AliasURL(remote.URL); ok {
+			fmt.Printf("  %s: %s → %s\n", remote.Name, remote.URL, aliased)
+			git.PlanSetRemoteURL(p, repo, remote.Name, remote.URL, aliased)
+			count++
+		} else if owner, ok := cfg.AliasProfile(remote.URL); ok && owner != profileName {
+			fmt.Printf("  %s: %s (⚠ goes through profile '%s', left unchanged)\n", remote.Name, remote.URL, owner)
+		} else {
+			fmt.Printf("  %s: %s (unchanged)\n", remote.Name, remote.URL)
+		}
+	}
+
+	if considered == 0 {
+		if repoFixAllRemotes {
+			fmt.Println("  no remotes")
+		} else {
+			fmt.Println("  no origin remote")
+		}
+	}
+	return count, nil
+}
@@ -3,14 +3,19 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 	"github.com/spf13/cobra"
 )
 
 var (
-	autoSSH        bool
-	skipPrompts    bool
-	version        = "2.0.0"
+	autoSSH     bool
+	skipPrompts bool
+	dryRun      bool
+	version     = "2.0.0"
 )
 
 var rootCmd = &cobra.Command{
@@ -33,4 +38,106 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&autoSSH, "auto-ssh", "s", false, "Automatically add SSH key to agent/keychain")
 	rootCmd.PersistentFlags().BoolVarP(&skipPrompts, "yes", "y", false, "Skip confirmation prompts")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print planned changes as a diff without applying them")
+}
+
+// mutating wraps a command that changes managed files. Its whole
+// load-modify-save cycle runs under the shared gh-switch lock, and every file
+// it may touch is snapshotted first so the change can be rolled back. A
+// config.json written by an older release is then saved migrated.
+func mutating(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		unlock, err := config.Lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if !dryRun {
+			if err := snapshotManagedFiles(cmd.CommandPath() + " " + strings.Join(args, " ")); err != nil {
+				return fmt.Errorf("failed to snapshot managed files: %w", err)
+			}
+
+			configMgr, err := config.NewConfigManager()
+			if err != nil {
+				return fmt.Errorf("failed to initialize config manager: %w", err)
+			}
+			if err := configMgr.SaveMigration(); err != nil {
+				return err
+			}
+		}
+
+		return run(cmd, args)
+	}
+}
+
+// applyPlan applies a command's change-set all-or-nothing. With --dry-run it
+// prints the plan instead and reports false so the caller can stop early.
+func applyPlan(p *plan.Plan) (bool, error) {
+	if dryRun {
+		p.Render(os.Stdout)
+		fmt.Println("Dry run: no changes were made.")
+		return false, nil
+	}
+
+	if err := p.Apply(); err != nil {
+		return false, fmt.Errorf("failed to apply changes: %w", err)
+	}
+
+	return true, nil
+}
+
+// applyRulesPlan schedules putting the managed includeIf directives in the
+// order cfg's rules require, then applies the plan like applyPlan. Commands
+// that add, remove or change rules use it instead of applyPlan.
+func applyRulesPlan(p *plan.Plan, cfg *config.Config) (bool, error) {
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return false, fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+	if err := gitMgr.PlanOrderIncludeIfs(p, cfg); err != nil {
+		return false, fmt.Errorf("failed to plan includeIf ordering: %w", err)
+	}
+
+	return applyPlan(p)
+}
+
+// saveProfileChange saves a modified profile and regenerates its
+// .gitconfig-<profile> file if one is in use. Like applyPlan, it reports
+// false when nothing was applied because of --dry-run.
+func saveProfileChange(cfg *config.Config, configMgr *config.ConfigManager, profile *config.Profile) (bool, error) {
+	p := plan.New()
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return false, fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+	if err := planRefreshProfileConfig(p, profile); err != nil {
+		return false, err
+	}
+
+	return applyPlan(p)
+}
+
+// planRefreshProfileConfig regenerates a profile's .gitconfig-<profile> file
+// if it was already created by a directory rule
+func planRefreshProfileConfig(p *plan.Plan, profile *config.Profile) error {
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	if _, err := os.Stat(gitMgr.ProfileConfigPath(profile.Name)); err != nil {
+		return nil
+	}
+	if err := gitMgr.PlanSetupProfile(p, profile); err != nil {
+		return fmt.Errorf("failed to plan profile Git config: %w", err)
+	}
+	return nil
+}
+
+// printSwitchHint reminds the user that the global config only picks up
+// profile changes on the next switch
+func printSwitchHint(cfg *config.Config, profileName string) {
+	if cfg.CurrentProfile == profileName {
+		fmt.Printf("  Run 'gh-switch switch %s' to apply the change to your global Git config.\n", profileName)
+	}
 }
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"text/tabwriter"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanFormat  string
+	scanWorkers int
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [root]",
+	Short: "Report the identity of every repository under a directory",
+	Long: `Find the Git repositories under root (default: the current directory),
+including worktrees and submodules, and report for each one the profile the
+rules select, the identity Git actually uses, its remotes, and whether the
+remotes' hosts and owners belong to that profile.
+
+Repositories where the rules and Git disagree are marked with ✗.
+
+Examples:
+  gh-switch scan ~/src
+  gh-switch scan ~/src --format json
+  gh-switch scan ~/src --workers 16`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runScan,
+}
+
+func init() {
+	scanCmd.Flags().StringVar(&scanFormat, "format", "table", "Output format: table or json")
+	scanCmd.Flags().IntVar(&scanWorkers, "workers", runtime.NumCPU(), "Number of directories and repositories processed in parallel")
+	rootCmd.AddCommand(scanCmd)
+}
+
+func runScan(cmd *cobra.Command, args []string) error {
+	if scanFormat != "table" && scanFormat != "json" {
+		return fmt.Errorf("unknown format '%s' (expected table or json)", scanFormat)
+	}
+
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	repos, err := scan.Run(cfg, absRoot, scanWorkers)
+	if err != nil {
+		return err
+	}
+
+	if scanFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(repos); err != nil {
+			return fmt.Errorf("failed to encode results: %w", err)
+		}
+		return nil
+	}
+
+	printScanTable(absRoot, repos)
+	return nil
+}
+
+// printScanTable prints one row per repository, then the problems found
+func printScanTable(root string, repos []scan.Repository) {
+	if len(repos) == 0 {
+		fmt.Printf("No Git repositories found under %s\n", root)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  \tREPOSITORY\tKIND\tPROFILE\tEMAIL\tREMOTE")
+	for _, repo := range repos {
+		marker := "✓"
+		if !repo.OK() {
+			marker = "✗"
+		}
+
+		profile := repo.Profile
+		if profile == "" {
+			profile = "-"
+		}
+		remote := "-"
+		if len(repo.Remotes) > 0 {
+			remote = repo.Remotes[0].URL
+			if len(repo.Remotes) > 1 {
+				remote += fmt.Sprintf(" (+%d)", len(repo.Remotes)-1)
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", marker, relativeTo(root, repo.Path), repo.Kind, profile, repo.Email, remote)
+	}
+	w.Flush()
+
+	failing := 0
+	for _, repo := range repos {
+		if repo.OK() {
+			continue
+		}
+		if failing == 0 {
+			fmt.Println("\nProblems:")
+		}
+		failing++
+
+		fmt.Printf("  %s\n", relativeTo(root, repo.Path))
+		if repo.Error != "" {
+			fmt.Printf("    ⚠ %s\n", repo.Error)
+		}
+		for _, problem := range repo.Problems {
+			fmt.Printf("    ✗ %s\n", problem)
+		}
+	}
+
+	fmt.Printf("\n%d repositories, %d with problems\n", len(repos), failing)
+}
+
+// relativeTo shortens path for display relative to root
+func relativeTo(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var shellOptions shell.Options
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init <bash|zsh|fish>",
+	Short: "Print shell integration that exports the profile for each directory",
+	Long: `Print a snippet that, whenever the working directory changes, exports the
+identity of the profile the directory and remote rules select for the
+enclosing repository:
+
+  GIT_AUTHOR_NAME, GIT_AUTHOR_EMAIL, GIT_COMMITTER_NAME, GIT_COMMITTER_EMAIL
+  GIT_SSH_COMMAND (for profiles with an SSH key)
+  GH_CONFIG_DIR (with --gh-config-dir)
+  GH_TOKEN (with --gh-token)
+
+Leaving the rules' directories restores the values the variables had before.
+Tools that ignore Git's includeIf, such as scripts calling the GitHub API,
+then pick up the right identity too. Nothing is exported in a repository
+where Git resolves a user.email outside the profile.
+
+GH_CONFIG_DIR and GH_TOKEN come from a gh config directory per profile,
+~/.config/gh-<profile>; log in to it once with
+GH_CONFIG_DIR=~/.config/gh-<profile> gh auth login.
+
+Add to your shell's startup file:
+  bash (~/.bashrc):             eval "$(gh-switch shell-init bash)"
+  zsh (~/.zshrc):               eval "$(gh-switch shell-init zsh)"
+  fish (config.fish):           gh-switch shell-init fish | source`,
+	ValidArgs: shell.Shells,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE:      runShellInit,
+}
+
+// shellEnvCmd is what the shell integration runs on directory changes
+var shellEnvCmd = &cobra.Command{
+	Use:          "shell-env <bash|zsh|fish>",
+	Short:        "Print the environment changes for the current directory",
+	Args:         cobra.ExactArgs(1),
+	Hidden:       true,
+	SilenceUsage: true,
+	RunE:         runShellEnv,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{shellInitCmd, shellEnvCmd} {
+		cmd.Flags().BoolVar(&shellOptions.GHConfigDir, "gh-config-dir", false, "Also export GH_CONFIG_DIR for the profile")
+		cmd.Flags().BoolVar(&shellOptions.GHToken, "gh-token", false, "Also export GH_TOKEN for the profile")
+		rootCmd.AddCommand(cmd)
+	}
+}
+
+func runShellInit(cmd *cobra.Command, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate gh-switch executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	var flags []string
+	if shellOptions.GHConfigDir {
+		flags = append(flags, "--gh-config-dir")
+	}
+	if shellOptions.GHToken {
+		flags = append(flags, "--gh-token")
+	}
+
+	snippet, err := shell.Init(args[0], executable, flags)
+	if err != nil {
+		return err
+	}
+	fmt.Print(snippet)
+	return nil
+}
+
+func runShellEnv(cmd *cobra.Command, args []string) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := shell.Rules(configMgr)
+	if err != nil {
+		return err
+	}
+
+	profile, err := shell.Select(cfg, dir)
+	if err != nil {
+		return err
+	}
+
+	changes, warnings, err := shell.Transition(os.LookupEnv, profile, shellOptions)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "⚠ gh-switch: %s\n", warning)
+	}
+
+	out, err := shell.Render(args[0], changes)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
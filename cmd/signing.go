@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	signingFormat  string
+	signingKey     string
+	signingProgram string
+	signingCommits bool
+	signingTags    bool
+)
+
+var signingCmd = &cobra.Command{
+	Use:   "signing",
+	Short: "Configure commit and tag signing for a profile",
+	Long: `Configure how a profile signs commits and tags: with OpenPGP (gpg), SSH keys
+or X.509 certificates (smimesign, gitsign).
+
+The generated .gitconfig-<profile> file and 'gh-switch switch' write the
+matching gpg.format, user.signingkey, gpg.*.program, commit.gpgsign and
+tag.gpgsign settings.
+
+Examples:
+  gh-switch signing set work --format ssh
+  gh-switch signing set work --format ssh --key ~/.ssh/id_work.pub --tags
+  gh-switch signing set work --format openpgp --key ABC123DEF456
+  gh-switch signing set work --format x509 --key me@company.com --program smimesign
+  gh-switch signing clear work`,
+}
+
+var signingSetCmd = &cobra.Command{
+	Use:   "set <profile>",
+	Short: "Set a profile's signing configuration",
+	Args:  cobra.ExactArgs(1),
+	RunE:  mutating(runSigningSet),
+}
+
+var signingClearCmd = &cobra.Command{
+	Use:   "clear <profile>",
+	Short: "Disable signing for a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  mutating(runSigningClear),
+}
+
+func init() {
+	signingSetCmd.Flags().StringVar(&signingFormat, "format", config.SigningFormatOpenPGP, "Signing format: openpgp, ssh or x509")
+	signingSetCmd.Flags().StringVar(&signingKey, "key", "", "Signing key: GPG key ID, SSH public key path or X.509 certificate ID (SSH defaults to the profile's key)")
+	signingSetCmd.Flags().StringVar(&signingProgram, "program", "", "Signing program, e.g. gpg2, smimesign or gitsign")
+	signingSetCmd.Flags().BoolVar(&signingCommits, "commits", true, "Sign commits")
+	signingSetCmd.Flags().BoolVar(&signingTags, "tags", false, "Sign tags")
+	signingCmd.AddCommand(signingSetCmd)
+	signingCmd.AddCommand(signingClearCmd)
+	rootCmd.AddCommand(signingCmd)
+}
+
+func runSigningSet(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	key := signingKey
+	if key == "" && signingFormat == config.SigningFormatSSH && profile.SSHKeyPath != "" {
+		key = profile.SSHKeyPath + ".pub"
+	}
+
+	signing := &config.Signing{
+		Format:  signingFormat,
+		Key:     key,
+		Program: signingProgram,
+		Commits: signingCommits,
+		Tags:    signingTags,
+	}
+	if err := signing.Validate(); err != nil {
+		return err
+	}
+	profile.Signing = signing
+
+	if applied, err := saveProfileChange(cfg, configMgr, profile); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Signing for profile '%s': %s key %s\n", profileName, signing.Format, signing.Key)
+	fmt.Printf("  Sign commits: %t, sign tags: %t\n", signing.Commits, signing.Tags)
+	printSwitchHint(cfg, profileName)
+	return nil
+}
+
+func runSigningClear(cmd *cobra.Command, args []string) error {
+	profileName := args[0]
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+	profile.Signing = nil
+
+	if applied, err := saveProfileChange(cfg, configMgr, profile); err != nil || !applied {
+		return err
+	}
+
+	fmt.Printf("✓ Signing disabled for profile '%s'\n", profileName)
+	printSwitchHint(cfg, profileName)
+	return nil
+}
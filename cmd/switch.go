@@ -5,6 +5,7 @@ import (
 
 	"github.com/calghar/gh-account-switcher/internal/config"
 	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 	"github.com/calghar/gh-account-switcher/internal/platform"
 	"github.com/calghar/gh-account-switcher/internal/ssh"
 	"github.com/spf13/cobra"
@@ -23,7 +24,7 @@ Examples:
   gh-switch --auto-ssh switch work
   gh-switch switch work john.contractor@company.com`,
 	Args: cobra.RangeArgs(1, 2),
-	RunE: runSwitch,
+	RunE: mutating(runSwitch),
 }
 
 func init() {
@@ -81,14 +82,25 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 	switchProfile := *profile
 	switchProfile.PrimaryEmail = emailToUse
 
-	if err := gitMgr.SwitchProfile(&switchProfile); err != nil {
-		return fmt.Errorf("failed to switch Git configuration: %w", err)
+	// Extra settings of the profile switched away from are removed
+	var previous *config.Profile
+	if cfg.CurrentProfile != "" {
+		previous = cfg.Profiles[cfg.CurrentProfile]
 	}
 
 	// Update current profile in config
 	cfg.CurrentProfile = profileName
-	if err := configMgr.Save(cfg); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+
+	p := plan.New()
+	if err := gitMgr.PlanSwitchProfile(p, &switchProfile, previous); err != nil {
+		return fmt.Errorf("failed to plan Git configuration: %w", err)
+	}
+	if err := configMgr.PlanSave(p, cfg); err != nil {
+		return fmt.Errorf("failed to plan configuration save: %w", err)
+	}
+
+	if applied, err := applyPlan(p); err != nil || !applied {
+		return err
 	}
 
 	// Success message
@@ -97,17 +109,17 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 	if profile.GitName != "" {
 		fmt.Printf("  Name: %s\n", profile.GitName)
 	}
-	if profile.GPGKey != "" {
-		fmt.Printf("  GPG signing: enabled\n")
+	if profile.Signing != nil {
+		fmt.Printf("  Signing: %s%s\n", profile.Signing.Format, signingScope(profile.Signing.Commits, profile.Signing.Tags))
 	}
 
 	// Handle SSH key if --auto-ssh flag is set
 	if autoSSH {
-		sshKeyPath := ssh.GetSSHKeyPath(profileName)
+		sshKeyPath := ssh.KeyPath(profile)
 
 		if !ssh.CheckSSHKeyExists(sshKeyPath) {
 			fmt.Printf("\n⚠ SSH key not found: %s\n", sshKeyPath)
-			fmt.Printf("  Generate one with: ssh-keygen -t ed25519 -f %s -C \"%s\"\n", sshKeyPath, emailToUse)
+			fmt.Printf("  Generate one with: gh-switch keygen %s\n", profileName)
 		} else {
 			// Get platform-specific keychain manager
 			keychainMgr, err := platform.GetKeychainManager()
@@ -132,18 +144,20 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 				}
 			}
 
-			// Show SSH host alias
-			hostAlias := ssh.GetHostAlias(profileName)
-			fmt.Printf("  Use this host in git URLs: git@%s:user/repo.git\n", hostAlias)
+			// Show SSH host aliases
+			for _, host := range profile.HostList() {
+				fmt.Printf("  Use this host in git URLs: %s\n", host.CloneURL(profileName, "user/repo"))
+			}
 		}
 	} else {
 		// Suggest SSH key setup
-		sshKeyPath := ssh.GetSSHKeyPath(profileName)
+		sshKeyPath := ssh.KeyPath(profile)
 		if ssh.CheckSSHKeyExists(sshKeyPath) {
 			fmt.Printf("\nSSH key available: %s\n", sshKeyPath)
 			fmt.Printf("  Add with: gh-switch --auto-ssh switch %s\n", profileName)
-			hostAlias := ssh.GetHostAlias(profileName)
-			fmt.Printf("  Use this host in git URLs: git@%s:user/repo.git\n", hostAlias)
+			for _, host := range profile.HostList() {
+				fmt.Printf("  Use this host in git URLs: %s\n", host.CloneURL(profileName, "user/repo"))
+			}
 		}
 	}
 
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which [path]",
+	Short: "Explain which identity Git uses in a directory",
+	Long: `Show the rule that selects a profile for the repository at path (default:
+the current directory), why it matched, and the identity Git actually
+resolves there, with the file each value comes from.
+
+Values that disagree with the selected profile are marked with ✗, and the
+command exits non-zero, so it can be used in scripts.
+
+Examples:
+  gh-switch which
+  gh-switch which ~/work/api`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runWhich,
+}
+
+// whichKeys are the settings compared against the selected profile
+var whichKeys = []string{"user.email", "user.name", "user.signingkey", "core.sshCommand"}
+
+func init() {
+	rootCmd.AddCommand(whichCmd)
+}
+
+func runWhich(cmd *cobra.Command, args []string) error {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		return fmt.Errorf("path not found: %s", absPath)
+	}
+
+	configMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	gitMgr, err := git.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize git manager: %w", err)
+	}
+
+	// Outside a repository, explain what a repository created here would get
+	gitDir, err := git.RepoGitDir(absPath)
+	inRepo := err == nil
+	if !inRepo {
+		gitDir = filepath.Join(absPath, ".git")
+	}
+
+	var remotes []string
+	if inRepo {
+		if remotes, err = git.RemoteURLs(absPath); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Path: %s\n", absPath)
+	if inRepo {
+		fmt.Printf("Repository: %s\n", gitDir)
+	} else {
+		fmt.Println("Repository: none (not inside a Git repository)")
+	}
+	if len(remotes) > 0 {
+		fmt.Printf("Remotes: %s\n", strings.Join(remotes, ", "))
+	}
+	fmt.Println()
+
+	profileName := explainRule(cfg, gitDir, remotes)
+
+	var profile *config.Profile
+	if profileName != "" {
+		if profile, err = cfg.GetProfile(profileName); err != nil {
+			fmt.Printf("⚠ Profile '%s' does not exist; run 'gh-switch doctor'\n", profileName)
+		} else {
+			fmt.Printf("Profile: %s (%s)\n", profile.Name, profile.PrimaryEmail)
+		}
+	}
+
+	if !inRepo {
+		fmt.Println("\nGit applies rules only inside repositories, so there is nothing to compare yet.")
+		return nil
+	}
+
+	values, err := git.EffectiveConfig(absPath, whichKeys...)
+	if err != nil {
+		return err
+	}
+
+	expected := make(map[string]string)
+	if profile != nil {
+		for _, kv := range git.ProfileValues(profile) {
+			expected[kv[0]] = kv[1]
+		}
+	}
+
+	fmt.Println("\nEffective Git configuration:")
+	disagreements := 0
+	for _, value := range values {
+		shown := value.Value
+		if !value.Set {
+			shown = "(not set)"
+		}
+
+		// The profile whose generated gitconfig the value came from, if any
+		source, fromProfile := gitMgr.ProfileForConfigPath(cfg, strings.TrimPrefix(value.Origin, "file:"))
+
+		marker, note := "•", ""
+		want, expects := expected[value.Key]
+		switch {
+		case expects && (!value.Set || value.Value != want):
+			marker, note = "✗", fmt.Sprintf("expected %s from profile '%s'", want, profile.Name)
+		case expects:
+			marker = "✓"
+		case profile != nil && fromProfile && source != profile.Name:
+			marker, note = "✗", fmt.Sprintf("set by profile '%s', which no rule selects here", source)
+		case profile != nil && value.Set:
+			note = fmt.Sprintf("not set by profile '%s'", profile.Name)
+		case profile == nil && fromProfile:
+			marker, note = "✗", fmt.Sprintf("set by profile '%s', although no rule selects a profile here", source)
+		}
+		if marker == "✗" {
+			disagreements++
+		}
+
+		fmt.Printf("  %s %-16s %s\n", marker, value.Key, shown)
+		if value.Set {
+			fmt.Printf("      from %s (%s)\n", value.Origin, value.Scope)
+		}
+		if note != "" {
+			fmt.Printf("      %s\n", note)
+		}
+	}
+
+	if disagreements > 0 {
+		fmt.Println("\nRun 'gh-switch doctor' to look for missing or misordered includeIf directives.")
+		return fmt.Errorf("git disagrees with gh-switch on %d setting(s)", disagreements)
+	}
+
+	return nil
+}
+
+// explainRule prints the rule selecting a profile for the repository and why
+// it matched, returning the profile name, or "" if no rule matches
+func explainRule(cfg *config.Config, gitDir string, remotes []string) string {
+	dirMatch, dirMatched := cfg.ExplainDirectoryMatch(gitDir)
+
+	// Remote rules are written after directory rules, so they take precedence
+	if rule, ok := cfg.MatchRemoteRule(remotes); ok {
+		url, _ := rule.MatchingURL(remotes)
+		fmt.Printf("Rule: remote rule %s → %s\n", rule.Pattern, rule.Profile)
+		fmt.Printf("  %s matches %s\n", git.RemoteCondition(rule.Pattern), url)
+		if dirMatched {
+			fmt.Printf("  overrides directory rule %s → %s\n", dirMatch.Rule.Path, dirMatch.Rule.Profile)
+		}
+		if err := git.CheckRemoteRuleSupport(); err != nil {
+			fmt.Printf("  ⚠ Git ignores this rule: %v\n", err)
+		}
+		return rule.Profile
+	}
+
+	if dirMatched {
+		fmt.Printf("Rule: directory rule %s → %s\n", dirMatch.Rule.Path, dirMatch.Rule.Profile)
+		fmt.Printf("  %s matches %s\n", git.GitDirCondition(*dirMatch.Rule, dirMatch.Pattern), dirMatch.GitDir)
+		return dirMatch.Rule.Profile
+	}
+
+	fmt.Println("Rule: none; Git uses the global identity")
+	return ""
+}
@@ -2,9 +2,14 @@ module github.com/calghar/gh-account-switcher
 
 go 1.24.4
 
-require github.com/spf13/cobra v1.10.1
+require (
+	github.com/spf13/cobra v1.10.1
+	golang.org/x/crypto v0.40.0
+	golang.org/x/term v0.33.0
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
+	golang.org/x/sys v0.34.0 // indirect
 )
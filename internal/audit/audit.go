@@ -0,0 +1,198 @@
+// Package audit checks the commit history of repositories against the
+// profiles their rules select
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+)
+
+// Issue kinds
+const (
+	// IssueOtherProfile flags an email belonging to a different profile than
+	// the one the rules select
+	IssueOtherProfile = "other-profile"
+	// IssueUnknownEmail flags an email that belongs to no profile
+	IssueUnknownEmail = "unknown-email"
+	// IssueUnsigned flags an unsigned commit where the profile signs commits
+	IssueUnsigned = "unsigned"
+)
+
+// Finding is a problem with one commit
+type Finding struct {
+	Commit         string `json:"commit"`
+	Date           string `json:"date"`
+	AuthorEmail    string `json:"author_email"`
+	CommitterEmail string `json:"committer_email"`
+	Signature      string `json:"signature"`
+	Subject        string `json:"subject"`
+	Issue          string `json:"issue"`
+	Detail         string `json:"detail"`
+}
+
+// Repository is the audit of one repository's history
+type Repository struct {
+	Path string `json:"path"`
+	// Profile is the profile the rules select; repositories without one
+	// are not audited
+	Profile  string    `json:"profile,omitempty"`
+	Rule     string    `json:"rule,omitempty"`
+	Commits  int       `json:"commits"`
+	Findings []Finding `json:"findings"`
+	// Error is set if the history could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// FlaggedCommits counts the distinct commits with findings
+func (r Repository) FlaggedCommits() int {
+	seen := make(map[string]bool)
+	for _, finding := range r.Findings {
+		seen[finding.Commit] = true
+	}
+	return len(seen)
+}
+
+// Options narrow the commits an audit reads
+type Options struct {
+	// Since is passed to git log --since, e.g. "2024-01-01" or "3 months ago"
+	Since string
+	// Authors are passed to git log --author
+	Authors []string
+	// Workers bounds how many repositories are read at once
+	Workers int
+}
+
+// Run audits the repositories at paths, reading up to opts.Workers of them
+// at a time. Results are sorted by profile, then path.
+func Run(cfg *config.Config, paths []string, opts Options) []Repository {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan string)
+	results := make(chan Repository)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				results <- auditRepository(cfg, path, opts)
+			}
+		}()
+	}
+
+	go func() {
+		for _, path := range paths {
+			jobs <- path
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var repos []Repository
+	for repo := range results {
+		repos = append(repos, repo)
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].Profile != repos[j].Profile {
+			return repos[i].Profile < repos[j].Profile
+		}
+		return repos[i].Path < repos[j].Path
+	})
+	return repos
+}
+
+// auditRepository checks every commit of one repository against the profile
+// its rules select
+func auditRepository(cfg *config.Config, path string, opts Options) Repository {
+	repo := Repository{Path: path, Findings: []Finding{}}
+
+	gitDir, err := git.RepoGitDir(path)
+	if err != nil {
+		repo.Error = err.Error()
+		return repo
+	}
+
+	urls, err := git.RemoteURLs(path)
+	if err != nil {
+		repo.Error = err.Error()
+		return repo
+	}
+
+	match, ok := cfg.MatchRule(gitDir, urls)
+	if !ok {
+		return repo
+	}
+	repo.Profile, repo.Rule = match.Profile, match.Rule
+
+	profile, err := cfg.GetProfile(match.Profile)
+	if err != nil {
+		repo.Error = fmt.Sprintf("%s points at missing profile '%s'", match.Rule, match.Profile)
+		return repo
+	}
+
+	commits, err := git.Commits(path, opts.Since, opts.Authors)
+	if err != nil {
+		repo.Error = err.Error()
+		return repo
+	}
+
+	repo.Commits = len(commits)
+	for _, commit := range commits {
+		repo.Findings = append(repo.Findings, checkCommit(cfg, profile, commit)...)
+	}
+	return repo
+}
+
+// checkCommit returns the findings for one commit in a repository whose
+// rules select profile
+func checkCommit(cfg *config.Config, profile *config.Profile, commit git.Commit) []Finding {
+	var findings []Finding
+	add := func(issue, detail string) {
+		findings = append(findings, Finding{
+			Commit:         commit.Hash,
+			Date:           commit.Date,
+			AuthorEmail:    commit.AuthorEmail,
+			CommitterEmail: commit.CommitterEmail,
+			Signature:      commit.Signature,
+			Subject:        commit.Subject,
+			Issue:          issue,
+			Detail:         detail,
+		})
+	}
+
+	for _, role := range commit.Identities() {
+		owners := cfg.ProfilesForEmail(role[1])
+		switch {
+		case len(owners) == 0:
+			add(IssueUnknownEmail, fmt.Sprintf("%s email %s belongs to no profile", role[0], role[1]))
+		case !contains(owners, profile.Name):
+			add(IssueOtherProfile, fmt.Sprintf("%s email %s belongs to profile '%s', not '%s'", role[0], role[1], strings.Join(owners, "', '"), profile.Name))
+		}
+	}
+
+	if profile.Signing != nil && profile.Signing.Commits && !commit.Signed() {
+		add(IssueUnsigned, fmt.Sprintf("unsigned, but profile '%s' signs commits", profile.Name))
+	}
+
+	return findings
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
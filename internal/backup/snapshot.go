@@ -0,0 +1,264 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/fileutil"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+)
+
+// maxSnapshots is how many snapshots are kept before the oldest are pruned
+const maxSnapshots = 50
+
+// manifestFile is the name of the metadata file inside each snapshot directory
+const manifestFile = "manifest.json"
+
+// FileEntry records the state of one file at snapshot time
+type FileEntry struct {
+	Path   string      `json:"path"`
+	Exists bool        `json:"exists"`
+	Mode   os.FileMode `json:"mode,omitempty"`
+	Size   int64       `json:"size,omitempty"`
+	Stored string      `json:"stored,omitempty"`
+}
+
+// Snapshot is a timestamped copy of every file a command was about to touch
+type Snapshot struct {
+	ID        string      `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Command   string      `json:"command"`
+	Files     []FileEntry `json:"files"`
+}
+
+// Manager stores and restores snapshots under ~/.github-switcher/backups
+type Manager struct {
+	backupDir string
+}
+
+// NewManager creates a new snapshot manager
+func NewManager() (*Manager, error) {
+	stateDir, err := config.StateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	backupDir := filepath.Join(stateDir, "backups")
+
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	return &Manager{backupDir: backupDir}, nil
+}
+
+// Create records the current contents of paths. Missing files are recorded
+// too, so that restoring removes files created after the snapshot.
+func (m *Manager) Create(command string, paths []string) (*Snapshot, error) {
+	now := time.Now().UTC()
+	id, dir, err := m.newSnapshotDir(now)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		ID:        id,
+		CreatedAt: now,
+		Command:   command,
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		entry, err := m.storeFile(dir, len(snapshot.Files), path)
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+		snapshot.Files = append(snapshot.Files, entry)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to marshal snapshot manifest: %w", err)
+	}
+	if err := fileutil.WriteFile(filepath.Join(dir, manifestFile), data, 0600); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+
+	m.prune()
+
+	return snapshot, nil
+}
+
+// newSnapshotDir allocates a unique, sortable snapshot ID and its directory
+func (m *Manager) newSnapshotDir(now time.Time) (string, string, error) {
+	base := now.Format("20060102-150405.000")
+	for i := 0; ; i++ {
+		id := base
+		if i > 0 {
+			id = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		dir := filepath.Join(m.backupDir, id)
+		err := os.Mkdir(dir, 0700)
+		if err == nil {
+			return id, dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", "", fmt.Errorf("failed to create snapshot directory: %w", err)
+		}
+	}
+}
+
+// storeFile copies one file into the snapshot directory
+func (m *Manager) storeFile(dir string, index int, path string) (FileEntry, error) {
+	entry := FileEntry{Path: path}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return entry, nil
+	}
+	if err != nil {
+		return entry, fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return entry, fmt.Errorf("cannot snapshot directory %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	stored := fmt.Sprintf("%02d-%s", index, filepath.Base(path))
+	if err := os.WriteFile(filepath.Join(dir, stored), data, 0600); err != nil {
+		return entry, fmt.Errorf("failed to store copy of %s: %w", path, err)
+	}
+
+	entry.Exists = true
+	entry.Mode = info.Mode().Perm()
+	entry.Size = info.Size()
+	entry.Stored = stored
+	return entry, nil
+}
+
+// List returns all snapshots, newest first
+func (m *Manager) List() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(m.backupDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		snapshot, err := m.Get(entry.Name())
+		if err != nil {
+			continue // Skip incomplete or foreign directories
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID > snapshots[j].ID
+	})
+
+	return snapshots, nil
+}
+
+// Get loads a snapshot by ID. The special ID "latest" selects the newest one.
+func (m *Manager) Get(id string) (*Snapshot, error) {
+	if id == "latest" {
+		snapshots, err := m.List()
+		if err != nil {
+			return nil, err
+		}
+		if len(snapshots) == 0 {
+			return nil, fmt.Errorf("no snapshots recorded yet")
+		}
+		return snapshots[0], nil
+	}
+
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return nil, fmt.Errorf("invalid snapshot id '%s'", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.backupDir, id, manifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("snapshot '%s' not found", id)
+		}
+		return nil, fmt.Errorf("failed to read snapshot '%s': %w", id, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot '%s': %w", id, err)
+	}
+
+	return &snapshot, nil
+}
+
+// ReadFile returns the stored contents of a file recorded in a snapshot
+func (m *Manager) ReadFile(snapshot *Snapshot, entry FileEntry) ([]byte, error) {
+	if !entry.Exists {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(m.backupDir, snapshot.ID, entry.Stored))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stored copy of %s: %w", entry.Path, err)
+	}
+	return data, nil
+}
+
+// PlanRestore schedules every file recorded in the snapshot to be put back
+// to its recorded state, deleting files that did not exist when the snapshot
+// was taken
+func (m *Manager) PlanRestore(p *plan.Plan, snapshot *Snapshot) error {
+	for _, entry := range snapshot.Files {
+		if !entry.Exists {
+			if err := p.RemoveFile(entry.Path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := m.ReadFile(snapshot, entry)
+		if err != nil {
+			return err
+		}
+
+		if err := p.WriteFile(entry.Path, data, entry.Mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prune removes the oldest snapshots beyond maxSnapshots
+func (m *Manager) prune() {
+	snapshots, err := m.List()
+	if err != nil || len(snapshots) <= maxSnapshots {
+		return
+	}
+
+	for _, snapshot := range snapshots[maxSnapshots:] {
+		os.RemoveAll(filepath.Join(m.backupDir, snapshot.ID))
+	}
+}
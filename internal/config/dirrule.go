@@ -0,0 +1,517 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/wildmatch"
+)
+
+// globChars are the characters that make a rule path a wildmatch pattern
+const globChars = "*?["
+
+// IsGlob reports whether a rule path is a glob pattern rather than a literal
+// directory
+func IsGlob(path string) bool {
+	return strings.ContainsAny(path, globChars)
+}
+
+// ExpandPath expands a leading ~ and makes a rule path absolute, keeping
+// any glob characters
+func ExpandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	return absPath, nil
+}
+
+// segments splits an absolute path into its non-empty segments
+func segments(path string) []string {
+	var result []string
+	for _, segment := range strings.Split(filepath.ToSlash(path), "/") {
+		if segment != "" {
+			result = append(result, segment)
+		}
+	}
+	return result
+}
+
+// normalize validates a rule and resolves its path and exclusions
+func (r *DirectoryRule) normalize() error {
+	path, err := ExpandPath(r.Path)
+	if err != nil {
+		return err
+	}
+	if len(segments(path)) == 0 {
+		return fmt.Errorf("directory rule cannot match the root directory")
+	}
+	r.Path = path
+
+	seen := make(map[string]bool)
+	var excludes []string
+	for _, exclude := range r.Exclude {
+		excludePath, err := ExpandPath(exclude)
+		if err != nil {
+			return err
+		}
+
+		rest, ok := strings.CutPrefix(filepath.ToSlash(excludePath), filepath.ToSlash(path)+"/")
+		if !ok || rest == "" {
+			return fmt.Errorf("excluded directory %s is not inside %s", excludePath, path)
+		}
+		if IsGlob(rest) {
+			return fmt.Errorf("excluded directory %s cannot contain glob characters below the rule's path", excludePath)
+		}
+		for _, segment := range segments(rest) {
+			if segment == ".git" {
+				return fmt.Errorf("cannot exclude a .git directory: %s", excludePath)
+			}
+		}
+
+		if !seen[excludePath] {
+			seen[excludePath] = true
+			excludes = append(excludes, excludePath)
+		}
+	}
+	sort.Strings(excludes)
+	r.Exclude = excludes
+
+	return nil
+}
+
+// GitDirPattern is a pattern for git's gitdir includeIf condition
+type GitDirPattern struct {
+	// Pattern is written as is after "gitdir:"; a trailing '/' makes git
+	// match everything below it
+	Pattern string
+}
+
+// Matches reports whether gitDir, a repository's .git directory, matches the
+// pattern the way git evaluates it
+func (p GitDirPattern) Matches(gitDir string, ignoreCase bool) bool {
+	pattern := p.Pattern
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	flags := wildmatch.Pathname
+	if ignoreCase {
+		flags |= wildmatch.CaseFold
+	}
+	return wildmatch.Match(pattern, filepath.ToSlash(gitDir), flags)
+}
+
+// GitDirPatterns returns the gitdir patterns that together select the rule's
+// repositories. A rule without exclusions is a single "<path>/" pattern.
+// Exclusions cannot be negated in git, so they are expressed as patterns
+// for everything under the path except the excluded subtrees.
+func (r DirectoryRule) GitDirPatterns() []GitDirPattern {
+	base := filepath.ToSlash(r.Path)
+	if len(r.Exclude) == 0 {
+		return []GitDirPattern{{Pattern: base + "/"}}
+	}
+
+	root := &segmentNode{}
+	for _, exclude := range r.Exclude {
+		rest := strings.TrimPrefix(filepath.ToSlash(exclude), base+"/")
+		node := root
+		for _, segment := range segments(rest) {
+			if r.IgnoreCase {
+				segment = strings.ToLower(segment)
+			}
+			node = node.child(segment)
+		}
+		node.excluded = true
+	}
+
+	var patterns []GitDirPattern
+	root.patterns(base, r.IgnoreCase, &patterns)
+	return patterns
+}
+
+// MatchesGitDir reports whether the rule selects the repository whose .git
+// directory is gitDir
+func (r DirectoryRule) MatchesGitDir(gitDir string) bool {
+	_, ok := r.MatchingPattern(gitDir)
+	return ok
+}
+
+// MatchingPattern returns the gitdir pattern that selects the repository
+// whose .git directory is gitDir
+func (r DirectoryRule) MatchingPattern(gitDir string) (GitDirPattern, bool) {
+	for _, pattern := range r.GitDirPatterns() {
+		if pattern.Matches(gitDir, r.IgnoreCase) {
+			return pattern, true
+		}
+	}
+	return GitDirPattern{}, false
+}
+
+// CompareSpecificity orders rules from least to most specific: rules with
+// more path segments, then with more literal segments, are more specific.
+// It returns a negative number if a is less specific than b.
+func CompareSpecificity(a, b DirectoryRule) int {
+	aSegments, bSegments := segments(a.Path), segments(b.Path)
+	if len(aSegments) != len(bSegments) {
+		return len(aSegments) - len(bSegments)
+	}
+	return literalSegments(aSegments) - literalSegments(bSegments)
+}
+
+// Overlaps reports whether some repository could be selected by both rules,
+// so that their relative order matters. Globs matching globs are assumed to
+// overlap, and exclusions are ignored.
+func (r DirectoryRule) Overlaps(other DirectoryRule) bool {
+	var flags wildmatch.Flags
+	if r.IgnoreCase || other.IgnoreCase {
+		flags = wildmatch.CaseFold
+	}
+
+	a, b := segments(r.Path), segments(other.Path)
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch {
+		case a[i] == "**" || b[i] == "**":
+			return true
+		case IsGlob(a[i]) && IsGlob(b[i]):
+			continue
+		case IsGlob(a[i]):
+			if !wildmatch.Match(a[i], b[i], flags) {
+				return false
+			}
+		case IsGlob(b[i]):
+			if !wildmatch.Match(b[i], a[i], flags) {
+				return false
+			}
+		default:
+			if !wildmatch.Match(escapeGlob(a[i]), b[i], flags) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// literalSegments counts the segments without glob characters
+func literalSegments(segs []string) int {
+	count := 0
+	for _, segment := range segs {
+		if !IsGlob(segment) {
+			count++
+		}
+	}
+	return count
+}
+
+// segmentNode is a trie of excluded paths, one level per path segment
+type segmentNode struct {
+	children map[string]*segmentNode
+	excluded bool
+}
+
+func (n *segmentNode) child(segment string) *segmentNode {
+	if n.children == nil {
+		n.children = make(map[string]*segmentNode)
+	}
+	child, ok := n.children[segment]
+	if !ok {
+		child = &segmentNode{}
+		n.children[segment] = child
+	}
+	return child
+}
+
+// patterns appends the gitdir patterns matching everything below prefix
+// that is not excluded
+func (n *segmentNode) patterns(prefix string, fold bool, out *[]GitDirPattern) {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Segments other than the children: the directory itself and everything
+	// below it
+	for _, segment := range complementSegments(names, fold) {
+		*out = append(*out,
+			GitDirPattern{Pattern: prefix + "/" + segment},
+			GitDirPattern{Pattern: prefix + "/" + segment + "/"},
+		)
+	}
+
+	for _, name := range names {
+		child := n.children[name]
+		if child.excluded {
+			continue
+		}
+		path := prefix + "/" + escapeGlob(name)
+		*out = append(*out, GitDirPattern{Pattern: path})
+		child.patterns(path, fold, out)
+	}
+}
+
+// charNode is a trie of segment names, one level per byte
+type charNode struct {
+	children map[byte]*charNode
+	terminal bool
+}
+
+// complementSegments returns wildmatch patterns matching any single path
+// segment except the given names
+func complementSegments(names []string, fold bool) []string {
+	root := &charNode{}
+	for _, name := range names {
+		node := root
+		for i := 0; i < len(name); i++ {
+			if node.children == nil {
+				node.children = make(map[byte]*charNode)
+			}
+			next, ok := node.children[name[i]]
+			if !ok {
+				next = &charNode{}
+				node.children[name[i]] = next
+			}
+			node = next
+		}
+		node.terminal = true
+	}
+
+	var patterns []string
+	root.complement("", fold, &patterns)
+	return patterns
+}
+
+// complement appends patterns for the segments starting with prefix that
+// are not names in the trie: prefix itself, prefix followed by a byte that
+// starts no name, and longer segments handled by the children
+func (n *charNode) complement(prefix string, fold bool, out *[]string) {
+	if len(n.children) == 0 {
+		// A name ends here: anything longer is allowed
+		*out = append(*out, prefix+"?*")
+		return
+	}
+
+	if prefix != "" && !n.terminal {
+		*out = append(*out, prefix)
+	}
+
+	chars := make([]byte, 0, len(n.children))
+	for c := range n.children {
+		chars = append(chars, c)
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+
+	var class strings.Builder
+	for _, c := range chars {
+		class.WriteString(escapeClassChar(c))
+		if fold && lowerByte(c) != upperByte(c) {
+			class.WriteString(escapeClassChar(upperByte(c)))
+		}
+	}
+	*out = append(*out, prefix+"[!"+class.String()+"]*")
+
+	for _, c := range chars {
+		child := n.children[c]
+		child.complement(prefix+escapeGlob(string(c)), fold, out)
+	}
+}
+
+// escapeGlob escapes wildmatch special characters in a literal
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`*?[\`, s[i]) >= 0 {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// escapeClassChar escapes a byte for use inside a bracket expression
+func escapeClassChar(c byte) string {
+	if strings.IndexByte(`]\!^-[`, c) >= 0 {
+		return `\` + string(c)
+	}
+	return string(c)
+}
+
+func lowerByte(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func upperByte(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
+
+// AddDirectoryRule adds a directory-to-profile mapping, or updates the rule
+// for the same path. Exclusions are added to those the rule already has.
+func (c *Config) AddDirectoryRule(rule DirectoryRule) (*DirectoryRule, error) {
+	if _, err := c.GetProfile(rule.Profile); err != nil {
+		return nil, err
+	}
+
+	if err := rule.normalize(); err != nil {
+		return nil, err
+	}
+
+	for i, existing := range c.DirectoryRules {
+		if existing.Path != rule.Path {
+			continue
+		}
+		merged := rule
+		merged.Exclude = append(append([]string(nil), existing.Exclude...), rule.Exclude...)
+		if err := merged.normalize(); err != nil {
+			return nil, err
+		}
+		c.DirectoryRules[i] = merged
+		return &c.DirectoryRules[i], nil
+	}
+
+	c.DirectoryRules = append(c.DirectoryRules, rule)
+	return &c.DirectoryRules[len(c.DirectoryRules)-1], nil
+}
+
+// RemoveDirectoryRule removes a directory rule
+func (c *Config) RemoveDirectoryRule(path string) error {
+	absPath, err := ExpandPath(path)
+	if err != nil {
+		return err
+	}
+
+	updatedRules := []DirectoryRule{}
+	found := false
+	for _, rule := range c.DirectoryRules {
+		if rule.Path != absPath {
+			updatedRules = append(updatedRules, rule)
+		} else {
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("directory rule for '%s' not found", absPath)
+	}
+
+	c.DirectoryRules = updatedRules
+	return nil
+}
+
+// RemoveExclusion removes an excluded directory from the rule that has it,
+// returning the rule as it was before the change
+func (c *Config) RemoveExclusion(path string) (DirectoryRule, error) {
+	absPath, err := ExpandPath(path)
+	if err != nil {
+		return DirectoryRule{}, err
+	}
+
+	for i, rule := range c.DirectoryRules {
+		for j, exclude := range rule.Exclude {
+			if exclude != absPath {
+				continue
+			}
+			previous := rule
+			previous.Exclude = append([]string(nil), rule.Exclude...)
+			c.DirectoryRules[i].Exclude = append(rule.Exclude[:j:j], rule.Exclude[j+1:]...)
+			return previous, nil
+		}
+	}
+
+	return DirectoryRule{}, fmt.Errorf("no directory rule excludes '%s'", absPath)
+}
+
+// FindDirectoryRule returns the rule for a path
+func (c *Config) FindDirectoryRule(path string) (*DirectoryRule, error) {
+	absPath, err := ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rule := range c.DirectoryRules {
+		if rule.Path == absPath {
+			return &c.DirectoryRules[i], nil
+		}
+	}
+	return nil, fmt.Errorf("directory rule for '%s' not found", absPath)
+}
+
+// DirectoryMatch explains why a directory rule selects a repository: Pattern
+// matched GitDir, which is the .git directory as given or with symlinks
+// resolved
+type DirectoryMatch struct {
+	Rule    *DirectoryRule
+	Pattern GitDirPattern
+	GitDir  string
+}
+
+// MatchDirectoryRule returns the most specific rule selecting the repository
+// whose .git directory is gitDir
+func (c *Config) MatchDirectoryRule(gitDir string) (*DirectoryRule, bool) {
+	match, ok := c.ExplainDirectoryMatch(gitDir)
+	return match.Rule, ok
+}
+
+// ExplainDirectoryMatch returns the most specific rule selecting the
+// repository whose .git directory is gitDir, with the pattern that matched.
+// Symlinks are matched both resolved and as given, like git does.
+func (c *Config) ExplainDirectoryMatch(gitDir string) (DirectoryMatch, bool) {
+	candidates := []string{gitDir}
+	if resolved, err := filepath.EvalSymlinks(gitDir); err == nil && resolved != gitDir {
+		candidates = append([]string{resolved}, candidates...)
+	}
+
+	var best DirectoryMatch
+	for i, rule := range c.DirectoryRules {
+		// Among equally specific rules, the later one wins, as in git
+		if best.Rule != nil && CompareSpecificity(rule, *best.Rule) < 0 {
+			continue
+		}
+		for _, candidate := range candidates {
+			if pattern, ok := rule.MatchingPattern(candidate); ok {
+				best = DirectoryMatch{Rule: &c.DirectoryRules[i], Pattern: pattern, GitDir: candidate}
+				break
+			}
+		}
+	}
+	return best, best.Rule != nil
+}
+
+// GetProfileForGitDir returns the profile name for the repository whose .git
+// directory is gitDir
+func (c *Config) GetProfileForGitDir(gitDir string) (string, error) {
+	if rule, ok := c.MatchDirectoryRule(gitDir); ok {
+		return rule.Profile, nil
+	}
+	return "", fmt.Errorf("no profile configured for repository: %s", gitDir)
+}
+
+// GetProfileForDirectory returns the profile name for a repository whose
+// working tree is dir
+func (c *Config) GetProfileForDirectory(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+
+	if rule, ok := c.MatchDirectoryRule(filepath.Join(absDir, ".git")); ok {
+		return rule.Profile, nil
+	}
+	return "", fmt.Errorf("no profile configured for directory: %s", absDir)
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComplementSegments(t *testing.T) {
+	tests := []struct {
+		names []string
+		fold  bool
+		want  []string
+	}{
+		{[]string{"b"}, false, []string{"[!b]*", "b?*"}},
+		{[]string{"b"}, true, []string{"[!bB]*", "b?*"}},
+		{[]string{"a", "ab"}, false, []string{"[!a]*", "a[!b]*", "ab?*"}},
+		{[]string{"ab", "ac"}, false, []string{"[!a]*", "a", "a[!bc]*", "ab?*", "ac?*"}},
+		{[]string{"a-b"}, false, []string{"[!a]*", "a", `a[!\-]*`, "a-", "a-[!b]*", "a-b?*"}},
+		{[]string{"x*"}, false, []string{"[!x]*", "x", `x[!*]*`, `x\*?*`}},
+	}
+
+	for _, tt := range tests {
+		if got := complementSegments(tt.names, tt.fold); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("complementSegments(%q, %v) = %q, want %q", tt.names, tt.fold, got, tt.want)
+		}
+	}
+}
+
+func TestGitDirPatterns(t *testing.T) {
+	tests := []struct {
+		rule DirectoryRule
+		want []string
+	}{
+		{
+			DirectoryRule{Path: "/w"},
+			[]string{"/w/"},
+		},
+		{
+			DirectoryRule{Path: "/w", Exclude: []string{"/w/x"}},
+			[]string{"/w/[!x]*", "/w/[!x]*/", "/w/x?*", "/w/x?*/"},
+		},
+		{
+			DirectoryRule{Path: "/w", Exclude: []string{"/w/a/b", "/w/a/c", "/w/d"}},
+			[]string{
+				"/w/[!ad]*", "/w/[!ad]*/", "/w/a?*", "/w/a?*/", "/w/d?*", "/w/d?*/",
+				"/w/a", "/w/a/[!bc]*", "/w/a/[!bc]*/", "/w/a/b?*", "/w/a/b?*/", "/w/a/c?*", "/w/a/c?*/",
+			},
+		},
+		{
+			DirectoryRule{Path: "/w", IgnoreCase: true, Exclude: []string{"/w/Ex"}},
+			[]string{"/w/[!eE]*", "/w/[!eE]*/", "/w/e", "/w/e/", "/w/e[!xX]*", "/w/e[!xX]*/", "/w/ex?*", "/w/ex?*/"},
+		},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, pattern := range tt.rule.GitDirPatterns() {
+			got = append(got, pattern.Pattern)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GitDirPatterns(%s, exclude %q) = %q, want %q", tt.rule.Path, tt.rule.Exclude, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesGitDir(t *testing.T) {
+	excluding := DirectoryRule{Path: "/w", Exclude: []string{"/w/a/b", "/w/a/c", "/w/d"}}
+	folded := DirectoryRule{Path: "/w", IgnoreCase: true, Exclude: []string{"/w/ex"}}
+
+	tests := []struct {
+		rule   DirectoryRule
+		gitDir string
+		want   bool
+	}{
+		{DirectoryRule{Path: "/w"}, "/w/r/.git", true},
+		{DirectoryRule{Path: "/w"}, "/w/.git", true},
+		{DirectoryRule{Path: "/w"}, "/wx/r/.git", false},
+		{DirectoryRule{Path: "/w"}, "/W/r/.git", false},
+		{DirectoryRule{Path: "/w", IgnoreCase: true}, "/W/r/.git", true},
+		{DirectoryRule{Path: "/w/*/src"}, "/w/org/src/r/.git", true},
+		{DirectoryRule{Path: "/w/*/src"}, "/w/org/sub/src/r/.git", false},
+		{DirectoryRule{Path: "/w/**/src"}, "/w/org/sub/src/r/.git", true},
+		{excluding, "/w/.git", true},
+		{excluding, "/w/r/.git", true},
+		{excluding, "/w/a/.git", true},
+		{excluding, "/w/a/r/.git", true},
+		{excluding, "/w/a/b/.git", false},
+		{excluding, "/w/a/b/deep/r/.git", false},
+		{excluding, "/w/a/bb/.git", true},
+		{excluding, "/w/a/c/.git", false},
+		{excluding, "/w/d/.git", false},
+		{excluding, "/w/dd/.git", true},
+		{excluding, "/x/d/.git", false},
+		{folded, "/w/EX/r/.git", false},
+		{folded, "/W/Ex/.git", false},
+		{folded, "/w/Exa/.git", true},
+		{folded, "/w/E/.git", true},
+		{folded, "/W/other/.git", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.MatchesGitDir(tt.gitDir); got != tt.want {
+			t.Errorf("%s (exclude %q, ignore case %v) MatchesGitDir(%s) = %v, want %v",
+				tt.rule.Path, tt.rule.Exclude, tt.rule.IgnoreCase, tt.gitDir, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeExclusions(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude []string
+		want    []string
+		wantErr bool
+	}{
+		{"sorted and deduplicated", []string{"/w/b", "/w/a", "/w/b/"}, []string{"/w/a", "/w/b"}, false},
+		{"cleaned", []string{"/w/a/../c"}, []string{"/w/c"}, false},
+		{"outside the rule", []string{"/x/a"}, nil, true},
+		{"sibling prefix", []string{"/wx"}, nil, true},
+		{"the rule itself", []string{"/w"}, nil, true},
+		{"glob", []string{"/w/*/a"}, nil, true},
+		{".git directory", []string{"/w/r/.git"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		rule := DirectoryRule{Path: "/w", Exclude: tt.exclude}
+		err := rule.normalize()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: normalize() error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(rule.Exclude, tt.want) {
+			t.Errorf("%s: normalize() excludes = %q, want %q", tt.name, rule.Exclude, tt.want)
+		}
+	}
+
+	root := DirectoryRule{Path: "/"}
+	if err := root.normalize(); err == nil {
+		t.Error("normalize() accepted a rule for the root directory")
+	}
+}
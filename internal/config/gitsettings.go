@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/gitconfig"
+)
+
+// GitConfigEntry is an extra git setting written into a profile's gitconfig.
+// A key may appear several times for multi-valued settings.
+type GitConfigEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// managedGitKeys are written by gh-switch from other profile fields
+var managedGitKeys = map[string]string{
+	"user.email":       "the profile's email",
+	"user.name":        "the profile's Git name",
+	"user.signingkey":  "gh-switch signing set",
+	"gpg.format":       "gh-switch signing set",
+	"gpg.program":      "gh-switch signing set --program",
+	"gpg.ssh.program":  "gh-switch signing set --program",
+	"gpg.x509.program": "gh-switch signing set --program",
+	"commit.gpgsign":   "gh-switch signing set",
+	"tag.gpgsign":      "gh-switch signing set",
+	"core.sshcommand":  "the profile's SSH key",
+}
+
+// ValidateGitConfigKey validates a settable key and returns its canonical form
+func ValidateGitConfigKey(key string) (string, error) {
+	canonical, err := gitconfig.CanonicalKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	if source, managed := managedGitKeys[canonical]; managed {
+		return "", fmt.Errorf("%s is managed by gh-switch; use %s instead", canonical, source)
+	}
+	section := canonical[:strings.Index(canonical, ".")]
+	if section == "include" || section == "includeif" {
+		return "", fmt.Errorf("%s cannot be set per profile", canonical)
+	}
+
+	return canonical, nil
+}
+
+// canonicalKey returns the canonical form of a stored key, or the key itself
+// if it cannot be parsed
+func canonicalKey(key string) string {
+	if canonical, err := gitconfig.CanonicalKey(key); err == nil {
+		return canonical
+	}
+	return key
+}
+
+// SortedGitConfig returns the profile's extra settings ordered by key, keeping
+// the order of values within a multi-valued key, so rendering is deterministic
+func (p *Profile) SortedGitConfig() []GitConfigEntry {
+	entries := make([]GitConfigEntry, len(p.GitConfig))
+	copy(entries, p.GitConfig)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return canonicalKey(entries[i].Key) < canonicalKey(entries[j].Key)
+	})
+	return entries
+}
+
+// GitConfigValues returns every value of a key in the profile's extra settings
+func (p *Profile) GitConfigValues(key string) []string {
+	canonical := canonicalKey(key)
+	var values []string
+	for _, entry := range p.GitConfig {
+		if canonicalKey(entry.Key) == canonical {
+			values = append(values, entry.Value)
+		}
+	}
+	return values
+}
+
+// SetGitConfig sets an extra git setting. Unless add is set, existing values
+// of the key are replaced. The key is stored as written; keys are compared
+// the way git does, ignoring the case of section and variable names.
+func (p *Profile) SetGitConfig(key, value string, add bool) error {
+	canonical, err := ValidateGitConfigKey(key)
+	if err != nil {
+		return err
+	}
+	if strings.ContainsAny(value, "\n\x00") {
+		return fmt.Errorf("value for %s cannot contain newlines", canonical)
+	}
+
+	if !add {
+		p.removeGitConfig(canonical, nil)
+	} else {
+		for _, existing := range p.GitConfigValues(canonical) {
+			if existing == value {
+				return fmt.Errorf("%s already has value '%s'", canonical, value)
+			}
+		}
+	}
+
+	p.GitConfig = append(p.GitConfig, GitConfigEntry{Key: key, Value: value})
+	return nil
+}
+
+// UnsetGitConfig removes every value of a key, or only the given value,
+// reporting how many entries were removed
+func (p *Profile) UnsetGitConfig(key string, value *string) (int, error) {
+	canonical, err := gitconfig.CanonicalKey(key)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := p.removeGitConfig(canonical, value)
+	if removed == 0 {
+		return 0, fmt.Errorf("%s is not set for profile '%s'", canonical, p.Name)
+	}
+	return removed, nil
+}
+
+// removeGitConfig drops the entries for a canonical key, optionally only
+// those with value
+func (p *Profile) removeGitConfig(key string, value *string) int {
+	var kept []GitConfigEntry
+	removed := 0
+	for _, entry := range p.GitConfig {
+		if canonicalKey(entry.Key) == key && (value == nil || entry.Value == *value) {
+			removed++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	p.GitConfig = kept
+	return removed
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported forges
+const (
+	ForgeGitHub    = "github"
+	ForgeGitLab    = "gitlab"
+	ForgeBitbucket = "bitbucket"
+	ForgeGitea     = "gitea"
+)
+
+// Host defaults
+const (
+	DefaultHostName      = "github.com"
+	DefaultSSHUser       = "git"
+	DefaultAliasTemplate = "{host}-{profile}"
+)
+
+// Host is a git forge a profile authenticates against over SSH
+type Host struct {
+	HostName string `json:"hostname"`
+	Forge    string `json:"forge,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	User     string `json:"user,omitempty"`
+	Alias    string `json:"alias,omitempty"`
+}
+
+// DefaultHost returns the github.com host used by profiles that do not name one
+func DefaultHost() Host {
+	return Host{HostName: DefaultHostName, Forge: ForgeGitHub}
+}
+
+// DetectForge guesses the forge from a hostname, defaulting to GitHub
+// (which covers GitHub Enterprise Server)
+func DetectForge(hostname string) string {
+	host := strings.ToLower(hostname)
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return ForgeGitLab
+	case strings.Contains(host, "bitbucket"):
+		return ForgeBitbucket
+	case strings.Contains(host, "gitea"), strings.Contains(host, "codeberg"), strings.Contains(host, "forgejo"):
+		return ForgeGitea
+	default:
+		return ForgeGitHub
+	}
+}
+
+// ForgeName returns the configured forge, or one detected from the hostname
+func (h Host) ForgeName() string {
+	if h.Forge != "" {
+		return h.Forge
+	}
+	return DetectForge(h.HostName)
+}
+
+// SSHUser returns the SSH user for the host
+func (h Host) SSHUser() string {
+	if h.User != "" {
+		return h.User
+	}
+	return DefaultSSHUser
+}
+
+// HostAlias expands the alias template for a profile. The template may use
+// {profile}, {host} and {forge}.
+func (h Host) HostAlias(profileName string) string {
+	template := h.Alias
+	if template == "" {
+		template = DefaultAliasTemplate
+	}
+
+	return strings.NewReplacer(
+		"{profile}", profileName,
+		"{host}", h.HostName,
+		"{forge}", h.ForgeName(),
+	).Replace(template)
+}
+
+// CloneURL returns the SSH clone URL for a repository ("owner/repo") through
+// the profile's host alias
+func (h Host) CloneURL(profileName, repo string) string {
+	return fmt.Sprintf("%s@%s:%s.git", h.SSHUser(), h.HostAlias(profileName), strings.TrimSuffix(repo, ".git"))
+}
+
+// SSHKeysURL returns the forge page where public SSH keys are registered
+func (h Host) SSHKeysURL() string {
+	switch h.ForgeName() {
+	case ForgeGitLab:
+		return fmt.Sprintf("https://%s/-/user_settings/ssh_keys", h.HostName)
+	case ForgeBitbucket:
+		return "https://bitbucket.org/account/settings/ssh-keys/"
+	case ForgeGitea:
+		return fmt.Sprintf("https://%s/user/settings/keys", h.HostName)
+	default:
+		return fmt.Sprintf("https://%s/settings/keys", h.HostName)
+	}
+}
+
+// Validate validates a host
+func (h Host) Validate() error {
+	if h.HostName == "" {
+		return fmt.Errorf("hostname cannot be empty")
+	}
+	if strings.ContainsAny(h.HostName, " \t/@:") {
+		return fmt.Errorf("invalid hostname: %s", h.HostName)
+	}
+
+	switch h.Forge {
+	case "", ForgeGitHub, ForgeGitLab, ForgeBitbucket, ForgeGitea:
+	default:
+		return fmt.Errorf("unknown forge '%s' (expected %s, %s, %s or %s)", h.Forge, ForgeGitHub, ForgeGitLab, ForgeBitbucket, ForgeGitea)
+	}
+
+	if h.Port < 0 || h.Port > 65535 {
+		return fmt.Errorf("invalid SSH port: %d", h.Port)
+	}
+	if strings.ContainsAny(h.User, " \t@") {
+		return fmt.Errorf("invalid SSH user: %s", h.User)
+	}
+	if h.Alias != "" && !strings.Contains(h.Alias, "{profile}") {
+		return fmt.Errorf("alias template '%s' must contain {profile}", h.Alias)
+	}
+	if strings.ContainsAny(h.HostAlias("profile"), " \t*?!") {
+		return fmt.Errorf("alias template '%s' produces an invalid SSH host alias", h.Alias)
+	}
+
+	return nil
+}
+
+// HostList returns the profile's hosts, falling back to github.com
+func (p *Profile) HostList() []Host {
+	if len(p.Hosts) == 0 {
+		return []Host{DefaultHost()}
+	}
+	return p.Hosts
+}
+
+// PrimaryHost returns the profile's first host
+func (p *Profile) PrimaryHost() Host {
+	return p.HostList()[0]
+}
+
+// FindHost returns the profile's host with the given hostname
+func (p *Profile) FindHost(hostname string) (Host, bool) {
+	for _, host := range p.HostList() {
+		if strings.EqualFold(host.HostName, hostname) {
+			return host, true
+		}
+	}
+	return Host{}, false
+}
+
+// AddHost adds a host to a profile, replacing an existing entry for the same hostname
+func (p *Profile) AddHost(host Host) error {
+	if err := host.Validate(); err != nil {
+		return err
+	}
+
+	hosts := p.HostList()
+	updated := make([]Host, 0, len(hosts)+1)
+	replaced := false
+	for _, existing := range hosts {
+		if strings.EqualFold(existing.HostName, host.HostName) {
+			updated = append(updated, host)
+			replaced = true
+		} else {
+			updated = append(updated, existing)
+		}
+	}
+	if !replaced {
+		updated = append(updated, host)
+	}
+
+	p.Hosts = updated
+	return nil
+}
+
+// RemoveHost removes a host from a profile; a profile keeps at least one host
+func (p *Profile) RemoveHost(hostname string) error {
+	var updated []Host
+	found := false
+	for _, host := range p.HostList() {
+		if strings.EqualFold(host.HostName, hostname) {
+			found = true
+		} else {
+			updated = append(updated, host)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("host '%s' not found in profile '%s'", hostname, p.Name)
+	}
+	if len(updated) == 0 {
+		return fmt.Errorf("cannot remove the only host of profile '%s'", p.Name)
+	}
+
+	p.Hosts = updated
+	return nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/calghar/gh-account-switcher/internal/fileutil"
+)
+
+// lockFileName is the advisory lock shared by every gh-switch process
+const lockFileName = "gh-switch.lock"
+
+// Lock acquires the advisory lock that serializes gh-switch processes.
+//
+// The config, git and ssh managers all take this lock around their writes, and
+// commands hold it for their whole load-modify-save cycle so that concurrent
+// invocations never interleave edits to config.json, ~/.gitconfig or
+// ~/.ssh/config. The lock is reentrant within a process. The returned function
+// releases it.
+func Lock() (func(), error) {
+	configDir, err := StateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := fileutil.Lock(filepath.Join(configDir, lockFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock configuration: %w", err)
+	}
+
+	return func() { _ = lock.Unlock() }, nil
+}
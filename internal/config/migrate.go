@@ -0,0 +1,233 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CurrentSchemaVersion is the config.json schema version written by this binary
+const CurrentSchemaVersion = 3
+
+// Migration upgrades a raw config document by exactly one schema version.
+//
+// Migrations operate on the decoded JSON document rather than on Config so
+// they keep working after the Go types have moved on.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+// migrations is the ordered registry of schema upgrades. Each entry upgrades
+// From to From+1; append new steps here when bumping CurrentSchemaVersion.
+var migrations = []Migration{
+	{
+		From:        0,
+		Description: "add schema_version and normalize profiles and directory rules",
+		Apply:       migrateV0ToV1,
+	},
+	{
+		From:        1,
+		Description: "give every profile an explicit github.com host",
+		Apply:       migrateV1ToV2,
+	},
+	{
+		From:        2,
+		Description: "move gpg_key into a signing section",
+		Apply:       migrateV2ToV3,
+	},
+}
+
+// SchemaTooNewError is returned when a config file was written by a newer gh-switch
+type SchemaTooNewError struct {
+	Version   int
+	Supported int
+}
+
+func (e *SchemaTooNewError) Error() string {
+	return fmt.Sprintf("config schema version %d is newer than the supported version %d; upgrade gh-switch to read this file", e.Version, e.Supported)
+}
+
+// Decode parses a config document of any supported schema version, upgrading
+// it in memory to CurrentSchemaVersion
+func Decode(data []byte) (*Config, error) {
+	config, _, err := decode(data)
+	return config, err
+}
+
+// decode parses and migrates a config document, also reporting the schema
+// version it was stored with
+func decode(data []byte) (*Config, int, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	version, err := schemaVersion(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := migrate(doc, version); err != nil {
+		return nil, version, err
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, version, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(migrated, &config); err != nil {
+		return nil, version, err
+	}
+
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]*Profile)
+	}
+
+	return &config, version, nil
+}
+
+// schemaVersion reads the schema_version field, treating a missing field as
+// the unversioned legacy format
+func schemaVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["schema_version"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+
+	number, ok := raw.(float64)
+	if !ok || number < 0 || number != float64(int(number)) {
+		return 0, fmt.Errorf("invalid schema_version: %v", raw)
+	}
+
+	return int(number), nil
+}
+
+// migrate applies every registered migration from version up to CurrentSchemaVersion
+func migrate(doc map[string]any, version int) error {
+	if version > CurrentSchemaVersion {
+		return &SchemaTooNewError{Version: version, Supported: CurrentSchemaVersion}
+	}
+
+	steps := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		steps[m.From] = m
+	}
+
+	for v := version; v < CurrentSchemaVersion; v++ {
+		step, ok := steps[v]
+		if !ok {
+			return fmt.Errorf("no migration registered from schema version %d", v)
+		}
+		if err := step.Apply(doc); err != nil {
+			return fmt.Errorf("migration from schema version %d failed (%s): %w", v, step.Description, err)
+		}
+		doc["schema_version"] = v + 1
+	}
+
+	return nil
+}
+
+// migrateV0ToV1 upgrades the unversioned format: profile names are taken from
+// their map keys, primary emails are guaranteed to be in the email list, and
+// a null rule list becomes an empty one
+func migrateV0ToV1(doc map[string]any) error {
+	profiles, _ := doc["profiles"].(map[string]any)
+	if profiles == nil {
+		profiles = map[string]any{}
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		profile, ok := profiles[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("profile '%s' is not an object", name)
+		}
+
+		profile["name"] = name
+
+		primary, _ := profile["primary_email"].(string)
+		emails, _ := profile["emails"].([]any)
+		if primary != "" {
+			found := false
+			for _, email := range emails {
+				if email == primary {
+					found = true
+					break
+				}
+			}
+			if !found {
+				emails = append([]any{primary}, emails...)
+			}
+		}
+		if emails == nil {
+			emails = []any{}
+		}
+		profile["emails"] = emails
+	}
+	doc["profiles"] = profiles
+
+	if rules, ok := doc["directory_rules"].([]any); !ok || rules == nil {
+		doc["directory_rules"] = []any{}
+	}
+
+	return nil
+}
+
+// migrateV1ToV2 records the github.com host every profile implicitly used
+// before hosts became configurable
+func migrateV1ToV2(doc map[string]any) error {
+	profiles, _ := doc["profiles"].(map[string]any)
+	for name, raw := range profiles {
+		profile, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("profile '%s' is not an object", name)
+		}
+
+		if hosts, ok := profile["hosts"].([]any); ok && len(hosts) > 0 {
+			continue
+		}
+		profile["hosts"] = []any{
+			map[string]any{"hostname": DefaultHostName, "forge": ForgeGitHub},
+		}
+	}
+
+	return nil
+}
+
+// migrateV2ToV3 replaces the GPG-only gpg_key field with an OpenPGP signing
+// section that signs commits, which is what gpg_key used to configure
+func migrateV2ToV3(doc map[string]any) error {
+	profiles, _ := doc["profiles"].(map[string]any)
+	for name, raw := range profiles {
+		profile, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("profile '%s' is not an object", name)
+		}
+
+		key, _ := profile["gpg_key"].(string)
+		delete(profile, "gpg_key")
+		if key == "" {
+			continue
+		}
+		profile["signing"] = map[string]any{
+			"format":  SigningFormatOpenPGP,
+			"key":     key,
+			"commits": true,
+			"tags":    false,
+		}
+	}
+
+	return nil
+}
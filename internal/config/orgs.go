@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HTTPS rewrite modes for organization URLs
+const (
+	// HTTPSRewriteAll sends fetches and pushes of https URLs through SSH
+	HTTPSRewriteAll = "all"
+	// HTTPSRewritePush fetches over https but pushes through SSH
+	HTTPSRewritePush = "push"
+	// HTTPSRewriteNone leaves https URLs alone
+	HTTPSRewriteNone = "none"
+)
+
+// normalizeOrg trims surrounding slashes and validates an org or owner name
+func normalizeOrg(org string) (string, error) {
+	org = strings.Trim(strings.TrimSpace(org), "/")
+	if org == "" {
+		return "", fmt.Errorf("organization cannot be empty")
+	}
+	if strings.ContainsAny(org, " \t:@\\\"") {
+		return "", fmt.Errorf("invalid organization: %s", org)
+	}
+	return org, nil
+}
+
+// ValidateHTTPSRewrite validates an HTTPS rewrite mode
+func ValidateHTTPSRewrite(mode string) error {
+	switch mode {
+	case "", HTTPSRewriteAll, HTTPSRewritePush, HTTPSRewriteNone:
+		return nil
+	default:
+		return fmt.Errorf("unknown HTTPS rewrite mode '%s' (expected %s, %s or %s)", mode, HTTPSRewriteAll, HTTPSRewritePush, HTTPSRewriteNone)
+	}
+}
+
+// AddOrg routes an organization's repositories through a profile. An
+// organization can only belong to one profile per host.
+func (c *Config) AddOrg(profileName, org string) error {
+	profile, err := c.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	org, err = normalizeOrg(org)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range profile.Orgs {
+		if strings.EqualFold(existing, org) {
+			return fmt.Errorf("organization '%s' already belongs to profile '%s'", org, profileName)
+		}
+	}
+
+	for name, other := range c.Profiles {
+		if name == profileName || !sharesHost(profile, other) {
+			continue
+		}
+		for _, existing := range other.Orgs {
+			if strings.EqualFold(existing, org) {
+				return fmt.Errorf("organization '%s' already belongs to profile '%s'", org, name)
+			}
+		}
+	}
+
+	profile.Orgs = append(profile.Orgs, org)
+	return nil
+}
+
+// RemoveOrg stops routing an organization through a profile
+func (c *Config) RemoveOrg(profileName, org string) error {
+	profile, err := c.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	org = strings.Trim(strings.TrimSpace(org), "/")
+	var updated []string
+	found := false
+	for _, existing := range profile.Orgs {
+		if strings.EqualFold(existing, org) {
+			found = true
+		} else {
+			updated = append(updated, existing)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("organization '%s' not found in profile '%s'", org, profileName)
+	}
+
+	profile.Orgs = updated
+	return nil
+}
+
+// ProfileForOrg returns the profile an organization on hostname is routed
+// through
+func (c *Config) ProfileForOrg(hostname, org string) (string, bool) {
+	for name, profile := range c.Profiles {
+		if _, ok := profile.FindHost(hostname); !ok {
+			continue
+		}
+		for _, existing := range profile.Orgs {
+			if strings.EqualFold(existing, org) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// sharesHost reports whether two profiles have a hostname in common
+func sharesHost(a, b *Profile) bool {
+	for _, hostA := range a.HostList() {
+		for _, hostB := range b.HostList() {
+			if strings.EqualFold(hostA.HostName, hostB.HostName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// URLRewrites returns the url.<base>.insteadOf and pushInsteadOf settings that
+// send the profile's organizations through its SSH host aliases, whichever
+// URL form they are written in
+func (p *Profile) URLRewrites() []GitConfigEntry {
+	orgs := append([]string(nil), p.Orgs...)
+	sort.Strings(orgs)
+
+	var entries []GitConfigEntry
+	for _, host := range p.HostList() {
+		user := host.SSHUser()
+		hostPort := host.HostName
+		if host.Port != 0 && host.Port != 22 {
+			hostPort = fmt.Sprintf("%s:%d", host.HostName, host.Port)
+		}
+
+		for _, org := range orgs {
+			base := fmt.Sprintf("url.%s@%s:%s/", user, host.HostAlias(p.Name), org)
+			https := fmt.Sprintf("https://%s/%s/", host.HostName, org)
+
+			entries = append(entries,
+				GitConfigEntry{Key: base + ".insteadOf", Value: fmt.Sprintf("%s@%s:%s/", user, host.HostName, org)},
+				GitConfigEntry{Key: base + ".insteadOf", Value: fmt.Sprintf("ssh://%s@%s/%s/", user, hostPort, org)},
+			)
+			switch p.HTTPSRewrite {
+			case "", HTTPSRewriteAll:
+				entries = append(entries, GitConfigEntry{Key: base + ".insteadOf", Value: https})
+			case HTTPSRewritePush:
+				entries = append(entries, GitConfigEntry{Key: base + ".pushInsteadOf", Value: https})
+			}
+		}
+	}
+	return entries
+}
+
+// GeneratedGitConfig returns every extra setting rendered for the profile:
+// its URL rewrites followed by the user's settings
+func (p *Profile) GeneratedGitConfig() []GitConfigEntry {
+	return append(p.URLRewrites(), p.SortedGitConfig()...)
+}
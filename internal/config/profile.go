@@ -2,32 +2,57 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"time"
+
+	"github.com/calghar/gh-account-switcher/internal/fileutil"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 )
 
 // Profile represents a GitHub account profile
 type Profile struct {
-	Name         string   `json:"name"`
-	Emails       []string `json:"emails"`
-	PrimaryEmail string   `json:"primary_email"`
-	GitName      string   `json:"git_name,omitempty"`
-	GPGKey       string   `json:"gpg_key,omitempty"`
-	SSHKeyPath   string   `json:"ssh_key_path,omitempty"`
+	Name         string           `json:"name"`
+	Emails       []string         `json:"emails"`
+	PrimaryEmail string           `json:"primary_email"`
+	GitName      string           `json:"git_name,omitempty"`
+	Signing      *Signing         `json:"signing,omitempty"`
+	SSHKeyPath   string           `json:"ssh_key_path,omitempty"`
+	Hosts        []Host           `json:"hosts,omitempty"`
+	GitConfig    []GitConfigEntry `json:"git_config,omitempty"`
+	Orgs         []string         `json:"orgs,omitempty"`
+	PushOwners   []string         `json:"push_owners,omitempty"`
+	HTTPSRewrite string           `json:"https_rewrite,omitempty"`
 }
 
-// DirectoryRule represents a directory-to-profile mapping
+// DirectoryRule represents a directory-to-profile mapping. Path is an
+// absolute directory or a glob pattern such as ~/clients/*/internal.
 type DirectoryRule struct {
 	Path    string `json:"path"`
 	Profile string `json:"profile"`
+	// IgnoreCase matches the path case-insensitively (gitdir/i)
+	IgnoreCase bool `json:"ignore_case,omitempty"`
+	// Exclude lists directories under Path the rule does not apply to
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// RemoteRule selects a profile for repositories whose remote URL matches a
+// git wildmatch pattern
+type RemoteRule struct {
+	Pattern string `json:"pattern"`
+	Profile string `json:"profile"`
 }
 
 // Config represents the application configuration
 type Config struct {
+	SchemaVersion  int                 `json:"schema_version"`
 	Profiles       map[string]*Profile `json:"profiles"`
 	DirectoryRules []DirectoryRule     `json:"directory_rules"`
+	RemoteRules    []RemoteRule        `json:"remote_rules,omitempty"`
 	CurrentProfile string              `json:"current_profile"`
 }
 
@@ -39,12 +64,11 @@ type ConfigManager struct {
 
 // NewConfigManager creates a new configuration manager
 func NewConfigManager() (*ConfigManager, error) {
-	homeDir, err := os.UserHomeDir()
+	configDir, err := StateDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
 
-	configDir := filepath.Join(homeDir, ".github-switcher")
 	configFile := filepath.Join(configDir, "config.json")
 
 	// Create config directory if it doesn't exist
@@ -58,11 +82,37 @@ func NewConfigManager() (*ConfigManager, error) {
 	}, nil
 }
 
-// Load reads the configuration from disk
+// StateDir returns the directory holding gh-switch's own state
+func StateDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".github-switcher"), nil
+}
+
+// HooksDir returns the global hooks directory 'gh-switch hooks install'
+// points core.hooksPath at
+func HooksDir() (string, error) {
+	stateDir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "hooks"), nil
+}
+
+// ConfigFile returns the path of config.json
+func (cm *ConfigManager) ConfigFile() string {
+	return cm.configFile
+}
+
+// Load reads the configuration from disk. Files written by older releases
+// are migrated in memory only; SaveMigration writes the upgrade back.
 func (cm *ConfigManager) Load() (*Config, error) {
 	// If config file doesn't exist, return empty config
 	if _, err := os.Stat(cm.configFile); os.IsNotExist(err) {
 		return &Config{
+			SchemaVersion:  CurrentSchemaVersion,
 			Profiles:       make(map[string]*Profile),
 			DirectoryRules: []DirectoryRule{},
 		}, nil
@@ -73,33 +123,121 @@ func (cm *ConfigManager) Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	config, _, err := cm.decode(data)
+	return config, err
+}
+
+// decode parses config.json, migrating it to the current schema version
+func (cm *ConfigManager) decode(data []byte) (*Config, int, error) {
+	config, version, err := decode(data)
+	if err != nil {
+		var tooNew *SchemaTooNewError
+		if errors.As(err, &tooNew) {
+			return nil, 0, fmt.Errorf("refusing to load %s: %w", cm.configFile, err)
+		}
+		return nil, 0, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return config, version, nil
+}
+
+// SaveMigration writes config.json back at the current schema version if an
+// older release wrote it, keeping the original as config.json.v{N}.bak.
+// Commands that change files call it so older files are only migrated once;
+// read-only commands and dry runs leave the file alone.
+func (cm *ConfigManager) SaveMigration() error {
+	unlock, err := Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	original, err := os.ReadFile(cm.configFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Files that don't parse are reported by the command loading them, and
+	// must not keep 'backup restore' from replacing them
+	config, version, err := cm.decode(original)
+	if err != nil || version >= CurrentSchemaVersion {
+		return nil
+	}
+
+	backupFile := fmt.Sprintf("%s.v%d.bak", cm.configFile, version)
+	if _, err := os.Stat(backupFile); err == nil {
+		backupFile = fmt.Sprintf("%s.v%d.%s.bak", cm.configFile, version, time.Now().UTC().Format("20060102T150405Z"))
 	}
 
-	// Initialize profiles map if nil
-	if config.Profiles == nil {
-		config.Profiles = make(map[string]*Profile)
+	if err := fileutil.WriteFile(backupFile, original, 0600); err != nil {
+		return fmt.Errorf("failed to back up config before migration: %w", err)
 	}
 
-	return &config, nil
+	if err := cm.Save(config); err != nil {
+		return fmt.Errorf("failed to save migrated config: %w", err)
+	}
+
+	return nil
 }
 
-// Save writes the configuration to disk
+// Save writes the configuration to disk atomically while holding the shared lock
 func (cm *ConfigManager) Save(config *Config) error {
-	data, err := json.MarshalIndent(config, "", "  ")
+	unlock, err := Lock()
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
+	}
+	defer unlock()
+
+	data, err := marshalConfig(config)
+	if err != nil {
+		return err
 	}
 
-	if err := os.WriteFile(cm.configFile, data, 0600); err != nil {
+	if err := fileutil.WriteFile(cm.configFile, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
+// PlanSave schedules the configuration to be written as part of a plan
+func (cm *ConfigManager) PlanSave(p *plan.Plan, config *Config) error {
+	data, err := marshalConfig(config)
+	if err != nil {
+		return err
+	}
+
+	return p.WriteFile(cm.configFile, data, 0600)
+}
+
+// marshalConfig encodes the configuration at the current schema version
+func marshalConfig(config *Config) ([]byte, error) {
+	config.SchemaVersion = CurrentSchemaVersion
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return data, nil
+}
+
+// HasEmail reports whether email is one of the profile's addresses, compared
+// case-insensitively
+func (p *Profile) HasEmail(email string) bool {
+	if strings.EqualFold(p.PrimaryEmail, email) {
+		return true
+	}
+	for _, candidate := range p.Emails {
+		if strings.EqualFold(candidate, email) {
+			return true
+		}
+	}
+	return false
+}
+
 // Validate validates a profile
 func (p *Profile) Validate() error {
 	if p.Name == "" {
@@ -121,9 +259,33 @@ func (p *Profile) Validate() error {
 		}
 	}
 
-	// Validate GPG key format if provided
-	if p.GPGKey != "" && !isValidGPGKey(p.GPGKey) {
-		return fmt.Errorf("invalid GPG key format: %s (expected 8+ hexadecimal characters)", p.GPGKey)
+	// Validate signing configuration if provided
+	if p.Signing != nil {
+		if err := p.Signing.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, host := range p.Hosts {
+		if err := host.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range p.GitConfig {
+		if _, err := ValidateGitConfigKey(entry.Key); err != nil {
+			return err
+		}
+	}
+
+	for _, org := range p.PushAllowList() {
+		if _, err := normalizeOrg(org); err != nil {
+			return err
+		}
+	}
+
+	if err := ValidateHTTPSRewrite(p.HTTPSRewrite); err != nil {
+		return err
 	}
 
 	return nil
@@ -159,6 +321,30 @@ func (c *Config) AddProfile(profile *Profile) error {
 		profile.Emails = append([]string{profile.PrimaryEmail}, profile.Emails...)
 	}
 
+	if len(profile.Hosts) == 0 {
+		profile.Hosts = []Host{DefaultHost()}
+	}
+
+	// SSH host aliases must be unique across profiles
+	aliases := make(map[string]string)
+	for _, host := range profile.Hosts {
+		alias := host.HostAlias(profile.Name)
+		if _, dup := aliases[alias]; dup {
+			return fmt.Errorf("profile '%s' uses SSH host alias '%s' more than once", profile.Name, alias)
+		}
+		aliases[alias] = host.HostName
+	}
+	for name, other := range c.Profiles {
+		if name == profile.Name {
+			continue
+		}
+		for _, host := range other.HostList() {
+			if _, dup := aliases[host.HostAlias(name)]; dup {
+				return fmt.Errorf("SSH host alias '%s' is already used by profile '%s'", host.HostAlias(name), name)
+			}
+		}
+	}
+
 	c.Profiles[profile.Name] = profile
 	return nil
 }
@@ -181,7 +367,7 @@ func (c *Config) RemoveProfile(name string) error {
 	delete(c.Profiles, name)
 
 	// Remove associated directory rules
-	var updatedRules []DirectoryRule
+	updatedRules := []DirectoryRule{}
 	for _, rule := range c.DirectoryRules {
 		if rule.Profile != name {
 			updatedRules = append(updatedRules, rule)
@@ -189,6 +375,14 @@ func (c *Config) RemoveProfile(name string) error {
 	}
 	c.DirectoryRules = updatedRules
 
+	var remoteRules []RemoteRule
+	for _, rule := range c.RemoteRules {
+		if rule.Profile != name {
+			remoteRules = append(remoteRules, rule)
+		}
+	}
+	c.RemoteRules = remoteRules
+
 	// Clear current profile if it's the one being removed
 	if c.CurrentProfile == name {
 		c.CurrentProfile = ""
@@ -247,89 +441,3 @@ func (c *Config) RemoveEmail(profileName, email string) error {
 	profile.Emails = updatedEmails
 	return nil
 }
-
-// AddDirectoryRule adds a directory-to-profile mapping
-func (c *Config) AddDirectoryRule(path, profileName string) error {
-	if _, err := c.GetProfile(profileName); err != nil {
-		return err
-	}
-
-	// Convert to absolute path
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		return fmt.Errorf("failed to resolve absolute path: %w", err)
-	}
-
-	// Check if rule already exists and update it
-	for i, rule := range c.DirectoryRules {
-		if rule.Path == absPath {
-			c.DirectoryRules[i].Profile = profileName
-			return nil
-		}
-	}
-
-	// Add new rule
-	c.DirectoryRules = append(c.DirectoryRules, DirectoryRule{
-		Path:    absPath,
-		Profile: profileName,
-	})
-
-	return nil
-}
-
-// RemoveDirectoryRule removes a directory rule
-func (c *Config) RemoveDirectoryRule(path string) error {
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		return fmt.Errorf("failed to resolve absolute path: %w", err)
-	}
-
-	var updatedRules []DirectoryRule
-	found := false
-	for _, rule := range c.DirectoryRules {
-		if rule.Path != absPath {
-			updatedRules = append(updatedRules, rule)
-		} else {
-			found = true
-		}
-	}
-
-	if !found {
-		return fmt.Errorf("directory rule for '%s' not found", absPath)
-	}
-
-	c.DirectoryRules = updatedRules
-	return nil
-}
-
-// GetProfileForDirectory returns the profile name for a given directory
-func (c *Config) GetProfileForDirectory(dir string) (string, error) {
-	absDir, err := filepath.Abs(dir)
-	if err != nil {
-		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
-	}
-
-	// Check for exact match first
-	for _, rule := range c.DirectoryRules {
-		if rule.Path == absDir {
-			return rule.Profile, nil
-		}
-	}
-
-	// Check for parent directory matches (longest match wins)
-	bestMatch := ""
-	bestLength := 0
-
-	for _, rule := range c.DirectoryRules {
-		if len(rule.Path) > bestLength && filepath.HasPrefix(absDir, rule.Path) {
-			bestMatch = rule.Profile
-			bestLength = len(rule.Path)
-		}
-	}
-
-	if bestMatch != "" {
-		return bestMatch, nil
-	}
-
-	return "", fmt.Errorf("no profile configured for directory: %s", absDir)
-}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddPushOwner allows pushes from a profile to an owner's repositories
+func (c *Config) AddPushOwner(profileName, owner string) error {
+	profile, err := c.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	owner, err = normalizeOrg(owner)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range profile.PushAllowList() {
+		if strings.EqualFold(existing, owner) {
+			return fmt.Errorf("profile '%s' already allows pushes to '%s'", profileName, owner)
+		}
+	}
+
+	profile.PushOwners = append(profile.PushOwners, owner)
+	return nil
+}
+
+// RemovePushOwner stops allowing pushes from a profile to an owner's
+// repositories
+func (c *Config) RemovePushOwner(profileName, owner string) error {
+	profile, err := c.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	owner = strings.Trim(strings.TrimSpace(owner), "/")
+	var updated []string
+	found := false
+	for _, existing := range profile.PushOwners {
+		if strings.EqualFold(existing, owner) {
+			found = true
+		} else {
+			updated = append(updated, existing)
+		}
+	}
+
+	if !found {
+		for _, org := range profile.Orgs {
+			if strings.EqualFold(org, owner) {
+				return fmt.Errorf("'%s' is routed through profile '%s'; remove it with 'gh-switch org remove %s %s'", owner, profileName, profileName, owner)
+			}
+		}
+		return fmt.Errorf("push owner '%s' not found in profile '%s'", owner, profileName)
+	}
+
+	profile.PushOwners = updated
+	return nil
+}
+
+// PushAllowList returns the owners a profile may push to: the organizations
+// routed through it, then its push owners. An empty list allows any owner.
+func (p *Profile) PushAllowList() []string {
+	return append(append([]string(nil), p.Orgs...), p.PushOwners...)
+}
+
+// AllowsPushTo reports whether the profile may push to an owner's
+// repositories
+func (p *Profile) AllowsPushTo(owner string) bool {
+	allowed := p.PushAllowList()
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, existing := range allowed {
+		if strings.EqualFold(existing, owner) {
+			return true
+		}
+	}
+	return false
+}
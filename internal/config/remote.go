@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/wildmatch"
+)
+
+// validateRemotePattern checks that a remote URL pattern can be written into
+// an includeIf condition
+func validateRemotePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("remote URL pattern cannot be empty")
+	}
+	if strings.ContainsAny(pattern, "\n\x00") {
+		return fmt.Errorf("remote URL pattern cannot contain newlines")
+	}
+	return nil
+}
+
+// AddRemoteRule adds a remote-URL-to-profile mapping, or updates the profile
+// of an existing pattern
+func (c *Config) AddRemoteRule(pattern, profileName string) error {
+	if _, err := c.GetProfile(profileName); err != nil {
+		return err
+	}
+
+	if err := validateRemotePattern(pattern); err != nil {
+		return err
+	}
+
+	for i, rule := range c.RemoteRules {
+		if rule.Pattern == pattern {
+			c.RemoteRules[i].Profile = profileName
+			return nil
+		}
+	}
+
+	c.RemoteRules = append(c.RemoteRules, RemoteRule{
+		Pattern: pattern,
+		Profile: profileName,
+	})
+	return nil
+}
+
+// RemoveRemoteRule removes a remote rule
+func (c *Config) RemoveRemoteRule(pattern string) error {
+	var updated []RemoteRule
+	found := false
+	for _, rule := range c.RemoteRules {
+		if rule.Pattern != pattern {
+			updated = append(updated, rule)
+		} else {
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("remote rule for '%s' not found", pattern)
+	}
+
+	c.RemoteRules = updated
+	return nil
+}
+
+// Matches reports whether any of the remote URLs matches the rule, using the
+// same wildmatch semantics as git's hasconfig:remote.*.url condition
+func (r RemoteRule) Matches(remoteURLs []string) bool {
+	_, ok := r.MatchingURL(remoteURLs)
+	return ok
+}
+
+// MatchingURL returns the first of the remote URLs that matches the rule
+func (r RemoteRule) MatchingURL(remoteURLs []string) (string, bool) {
+	for _, url := range remoteURLs {
+		if wildmatch.Match(r.Pattern, url, wildmatch.Pathname) {
+			return url, true
+		}
+	}
+	return "", false
+}
+
+// MatchRemoteRule returns the rule selecting a repository with the given
+// remote URLs. As in git, where later includes override earlier ones, the
+// last matching rule wins.
+func (c *Config) MatchRemoteRule(remoteURLs []string) (*RemoteRule, bool) {
+	for i := len(c.RemoteRules) - 1; i >= 0; i-- {
+		if c.RemoteRules[i].Matches(remoteURLs) {
+			return &c.RemoteRules[i], true
+		}
+	}
+	return nil, false
+}
+
+// GetProfileForRemotes returns the profile for a repository with the given
+// remote URLs
+func (c *Config) GetProfileForRemotes(remoteURLs []string) (string, error) {
+	if rule, ok := c.MatchRemoteRule(remoteURLs); ok {
+		return rule.Profile, nil
+	}
+	return "", fmt.Errorf("no profile configured for remotes: %s", strings.Join(remoteURLs, ", "))
+}
+
+// RuleMatch is the rule selecting a repository's profile
+type RuleMatch struct {
+	Profile string
+	// Rule describes the rule, e.g. "directory rule /home/me/work"
+	Rule string
+}
+
+// MatchRule returns the rule git applies to a repository whose .git
+// directory is gitDir and which has the given remote URLs. Remote rules are
+// written after directory rules in the global gitconfig, so they take
+// precedence.
+func (c *Config) MatchRule(gitDir string, remoteURLs []string) (RuleMatch, bool) {
+	if rule, ok := c.MatchRemoteRule(remoteURLs); ok {
+		return RuleMatch{Profile: rule.Profile, Rule: "remote rule " + rule.Pattern}, true
+	}
+	if rule, ok := c.MatchDirectoryRule(gitDir); ok {
+		return RuleMatch{Profile: rule.Profile, Rule: "directory rule " + rule.Path}, true
+	}
+	return RuleMatch{}, false
+}
+
+// ResolveProfile returns the profile git selects for a repository in dir with
+// the given remote URLs
+func (c *Config) ResolveProfile(dir string, remoteURLs []string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+
+	if match, ok := c.MatchRule(filepath.Join(absDir, ".git"), remoteURLs); ok {
+		return match.Profile, nil
+	}
+	return "", fmt.Errorf("no profile configured for directory: %s", absDir)
+}
+
+// ProfilesForEmail returns the names of the profiles that have an email
+// address, compared case-insensitively, sorted by name
+func (c *Config) ProfilesForEmail(email string) []string {
+	var names []string
+	for name, profile := range c.Profiles {
+		if profile.HasEmail(email) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RemoteURL is a parsed SSH or HTTPS git remote URL
+type RemoteURL struct {
+	// Scheme is "ssh" for both ssh:// and scp-like user@host:path URLs
+	Scheme string
+	User   string
+	Host   string
+	Port   int
+	// Path is the repository path without leading or trailing slashes,
+	// e.g. "owner/repo.git"
+	Path string
+}
+
+// ParseRemoteURL parses ssh://, https:// and scp-like user@host:path remote
+// URLs. Other forms, such as local paths, are not recognized.
+func ParseRemoteURL(url string) (RemoteURL, bool) {
+	var parsed RemoteURL
+	var authority, path string
+
+	if scheme, rest, ok := strings.Cut(url, "://"); ok {
+		switch scheme {
+		case "ssh", "https", "http":
+			parsed.Scheme = scheme
+		default:
+			return RemoteURL{}, false
+		}
+		authority, path, _ = strings.Cut(rest, "/")
+	} else {
+		// scp-like syntax needs a colon before any slash
+		colon := strings.Index(url, ":")
+		if colon <= 0 || strings.Contains(url[:colon], "/") {
+			return RemoteURL{}, false
+		}
+		parsed.Scheme = "ssh"
+		authority, path = url[:colon], url[colon+1:]
+	}
+
+	if user, host, ok := strings.Cut(authority, "@"); ok {
+		parsed.User, authority = user, host
+	}
+	// Only URLs with a scheme can carry a port
+	if host, port, ok := strings.Cut(authority, ":"); ok && strings.Contains(url, "://") {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return RemoteURL{}, false
+		}
+		authority, parsed.Port = host, n
+	}
+	parsed.Host = authority
+	parsed.Path = strings.Trim(path, "/")
+
+	if parsed.Host == "" || parsed.Path == "" {
+		return RemoteURL{}, false
+	}
+	return parsed, true
+}
+
+// pointsAt reports whether the URL addresses the host directly, on the
+// host's SSH port for SSH URLs
+func (u RemoteURL) pointsAt(h Host) bool {
+	if !strings.EqualFold(u.Host, h.HostName) {
+		return false
+	}
+	if u.Scheme != "ssh" {
+		return true
+	}
+
+	port := h.Port
+	if port == 0 {
+		port = 22
+	}
+	return u.Port == port || u.Port == 0 && port == 22
+}
+
+// AliasURL returns the SSH URL through the profile's host alias for a remote
+// URL that addresses the host directly over SSH or HTTPS
+func (h Host) AliasURL(profileName, url string) (string, bool) {
+	parsed, ok := ParseRemoteURL(url)
+	if !ok || !parsed.pointsAt(h) {
+		return "", false
+	}
+	return h.CloneURL(profileName, parsed.Path), true
+}
+
+// UsesAlias reports whether a remote URL goes through the profile's host alias
+func (h Host) UsesAlias(profileName, url string) bool {
+	parsed, ok := ParseRemoteURL(url)
+	return ok && parsed.Scheme == "ssh" && parsed.Host == h.HostAlias(profileName)
+}
+
+// AliasURL returns a remote URL rewritten to go through one of the profile's
+// host aliases, if it addresses one of the profile's hosts directly
+func (p *Profile) AliasURL(url string) (string, bool) {
+	for _, host := range p.HostList() {
+		if aliased, ok := host.AliasURL(p.Name, url); ok {
+			return aliased, true
+		}
+	}
+	return "", false
+}
+
+// Owner returns the user or organization owning the repository: the first
+// segment of the path
+func (u RemoteURL) Owner() string {
+	owner, _, _ := strings.Cut(u.Path, "/")
+	return owner
+}
+
+// ResolveRemoteURL parses a remote URL, replacing a profile's host alias with
+// the hostname it stands for. It also returns the profile whose alias the
+// URL goes through, if any.
+func (c *Config) ResolveRemoteURL(url string) (RemoteURL, string, bool) {
+	parsed, ok := ParseRemoteURL(url)
+	if !ok {
+		return RemoteURL{}, "", false
+	}
+
+	for name, profile := range c.Profiles {
+		for _, host := range profile.HostList() {
+			if host.UsesAlias(name, url) {
+				parsed.Host = host.HostName
+				return parsed, name, true
+			}
+		}
+	}
+	return parsed, "", true
+}
+
+// AliasProfile returns the profile whose host alias a remote URL goes through
+func (c *Config) AliasProfile(url string) (string, bool) {
+	_, name, ok := c.ResolveRemoteURL(url)
+	return name, ok && name != ""
+}
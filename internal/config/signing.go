@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Signing formats, as accepted by git's gpg.format
+const (
+	SigningFormatOpenPGP = "openpgp"
+	SigningFormatSSH     = "ssh"
+	SigningFormatX509    = "x509"
+)
+
+// Signing describes how a profile signs commits and tags
+type Signing struct {
+	Format  string `json:"format"`
+	Key     string `json:"key"`
+	Program string `json:"program,omitempty"`
+	Commits bool   `json:"commits"`
+	Tags    bool   `json:"tags"`
+}
+
+// Validate validates a signing configuration
+func (s *Signing) Validate() error {
+	switch s.Format {
+	case SigningFormatOpenPGP:
+		if !isValidGPGKey(s.Key) {
+			return fmt.Errorf("invalid GPG key format: %s (expected 8+ hexadecimal characters)", s.Key)
+		}
+	case SigningFormatSSH:
+		if s.Key == "" {
+			return fmt.Errorf("SSH signing requires a key: a public key path or key::<public key>")
+		}
+		if !strings.HasPrefix(s.Key, "key::") && !strings.HasPrefix(s.Key, "ssh-") && !strings.ContainsAny(s.Key, `/\`) {
+			return fmt.Errorf("invalid SSH signing key: %s (expected a key path, a public key or key::<public key>)", s.Key)
+		}
+	case SigningFormatX509:
+		if s.Key == "" {
+			return fmt.Errorf("X.509 signing requires a key: a certificate ID or email")
+		}
+	default:
+		return fmt.Errorf("unknown signing format '%s' (expected %s, %s or %s)", s.Format, SigningFormatOpenPGP, SigningFormatSSH, SigningFormatX509)
+	}
+
+	if strings.ContainsAny(s.Key+s.Program, "\n\r") {
+		return fmt.Errorf("signing key and program cannot contain newlines")
+	}
+
+	return nil
+}
+
+// ProgramKey returns the git config key holding the signing program for the format
+func (s *Signing) ProgramKey() string {
+	if s.Format == SigningFormatOpenPGP {
+		return "gpg.program"
+	}
+	return fmt.Sprintf("gpg.%s.program", s.Format)
+}
+
+// GitConfig returns the git config values that enable the signing setup
+func (s *Signing) GitConfig() [][2]string {
+	values := [][2]string{
+		{"gpg.format", s.Format},
+		{"user.signingkey", s.Key},
+	}
+	if s.Program != "" {
+		values = append(values, [2]string{s.ProgramKey(), s.Program})
+	}
+	values = append(values,
+		[2]string{"commit.gpgsign", fmt.Sprintf("%t", s.Commits)},
+		[2]string{"tag.gpgsign", fmt.Sprintf("%t", s.Tags)},
+	)
+	return values
+}
@@ -0,0 +1,443 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/gpg"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/calghar/gh-account-switcher/internal/ssh"
+)
+
+// DefaultChecks returns the built-in checks in the order they are run
+func DefaultChecks() []Check {
+	return []Check{
+		ruleProfileCheck{},
+		ruleIncludeIfCheck{},
+		includeIfTargetCheck{},
+		orphanIncludeIfCheck{},
+		includeIfOrderCheck{},
+		remoteRuleVersionCheck{},
+		sshEntryCheck{},
+		sshKeyCheck{},
+		signingKeyCheck{},
+	}
+}
+
+// sortedProfiles returns profiles ordered by name for stable output
+func sortedProfiles(cfg *config.Config) []*config.Profile {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	profiles := make([]*config.Profile, 0, len(names))
+	for _, name := range names {
+		profiles = append(profiles, cfg.Profiles[name])
+	}
+	return profiles
+}
+
+// ruleProfileCheck flags directory and remote rules pointing at profiles that
+// don't exist
+type ruleProfileCheck struct{}
+
+func (ruleProfileCheck) Name() string { return "rule-profiles" }
+
+func (ruleProfileCheck) Run(env *Env) ([]Finding, error) {
+	var findings []Finding
+	for _, rule := range env.Config.DirectoryRules {
+		if _, err := env.Config.GetProfile(rule.Profile); err == nil {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Severity:       Error,
+			Message:        fmt.Sprintf("Directory rule %s points at missing profile '%s'", rule.Path, rule.Profile),
+			FixDescription: "remove the directory rule and its includeIf directives",
+			Fix: func(p *plan.Plan) error {
+				if err := env.Config.RemoveDirectoryRule(rule.Path); err != nil {
+					return err
+				}
+				return env.Git.PlanRemoveDirectoryIncludeIf(p, rule)
+			},
+		})
+	}
+
+	for _, rule := range env.Config.RemoteRules {
+		if _, err := env.Config.GetProfile(rule.Profile); err == nil {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Severity:       Error,
+			Message:        fmt.Sprintf("Remote rule %s points at missing profile '%s'", rule.Pattern, rule.Profile),
+			FixDescription: "remove the remote rule and its includeIf directive",
+			Fix: func(p *plan.Plan) error {
+				if err := env.Config.RemoveRemoteRule(rule.Pattern); err != nil {
+					return err
+				}
+				return env.Git.PlanRemoveManagedIncludeIf(p, git.RemoteCondition(rule.Pattern), rule.Profile)
+			},
+		})
+	}
+	return findings, nil
+}
+
+// ruleIncludeIfCheck flags rules with no matching managed includeIf
+// directive. User-authored sections with the same condition don't count.
+type ruleIncludeIfCheck struct{}
+
+func (ruleIncludeIfCheck) Name() string { return "rule-includeif" }
+
+func (ruleIncludeIfCheck) Run(env *Env) ([]Finding, error) {
+	managed, err := env.Git.ManagedIncludeIfs(env.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string][]git.IncludeIf, len(managed))
+	for _, include := range managed {
+		existing[include.Condition] = append(existing[include.Condition], include)
+	}
+
+	rules, err := git.RuleIncludes(env.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, rule := range rules {
+		profile := env.Config.Profiles[rule.Profile]
+
+		expected := env.Git.ProfileConfigPath(profile.Name)
+		includes := existing[rule.Condition]
+		var actual *git.IncludeIf
+		for i := range includes {
+			actual = &includes[i]
+			if actual.Profile == profile.Name && actual.Path == expected {
+				break
+			}
+		}
+		if actual != nil && actual.Profile == profile.Name && actual.Path == expected {
+			continue
+		}
+
+		var message string
+		switch {
+		case actual == nil:
+			message = fmt.Sprintf("%s has no includeIf directive in ~/.gitconfig", capitalize(rule.Rule))
+		case actual.Profile != profile.Name:
+			message = fmt.Sprintf("includeIf for %s belongs to profile '%s' instead of '%s'", rule.Rule, actual.Profile, profile.Name)
+		default:
+			message = fmt.Sprintf("includeIf for %s points at %s instead of %s", rule.Rule, actual.Path, expected)
+		}
+
+		condition := rule.Condition
+		findings = append(findings, Finding{
+			Severity:       Error,
+			Message:        message,
+			FixDescription: fmt.Sprintf("regenerate the includeIf directive for profile '%s'", profile.Name),
+			Fix: func(p *plan.Plan) error {
+				return env.Git.PlanSetupInclude(p, profile, condition)
+			},
+		})
+	}
+	return findings, nil
+}
+
+// includeIfTargetCheck flags includeIf directives whose target file is missing
+type includeIfTargetCheck struct{}
+
+func (includeIfTargetCheck) Name() string { return "includeif-targets" }
+
+func (includeIfTargetCheck) Run(env *Env) ([]Finding, error) {
+	includes, err := env.Git.ListIncludeIfs(env.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rules by condition, to decide between regenerating and removing
+	rules := make(map[string]git.RuleInclude)
+	ruleIncludes, err := git.RuleIncludes(env.Config)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range ruleIncludes {
+		rules[rule.Condition] = rule
+	}
+
+	var findings []Finding
+	for _, include := range includes {
+		if _, err := os.Stat(include.Path); err == nil {
+			continue
+		}
+
+		finding := Finding{
+			Severity: Warning,
+			Message:  fmt.Sprintf("includeIf %s points at missing file %s", include.Condition, include.Path),
+		}
+
+		// Only repair directives that gh-switch generated
+		if !include.Managed {
+			findings = append(findings, finding)
+			continue
+		}
+
+		// Managed directives without a rule are reported by orphan-includeif
+		rule, hasRule := rules[include.Condition]
+		if !hasRule || rule.Profile != include.Profile {
+			continue
+		}
+		profile := env.Config.Profiles[rule.Profile]
+
+		finding.Severity = Error
+		finding.FixDescription = fmt.Sprintf("regenerate %s", env.Git.ProfileConfigPath(profile.Name))
+		finding.Fix = func(p *plan.Plan) error {
+			return env.Git.PlanSetupInclude(p, profile, rule.Condition)
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// remoteRuleVersionCheck flags remote rules the installed git ignores
+type remoteRuleVersionCheck struct{}
+
+func (remoteRuleVersionCheck) Name() string { return "git-version" }
+
+func (remoteRuleVersionCheck) Run(env *Env) ([]Finding, error) {
+	if len(env.Config.RemoteRules) == 0 {
+		return nil, nil
+	}
+
+	if err := git.CheckRemoteRuleSupport(); err != nil {
+		return []Finding{{
+			Severity: Warning,
+			Message:  fmt.Sprintf("%d remote rule(s) have no effect: %v", len(env.Config.RemoteRules), err),
+		}}, nil
+	}
+	return nil, nil
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// orphanIncludeIfCheck flags managed includeIf directives no rule accounts for
+type orphanIncludeIfCheck struct{}
+
+func (orphanIncludeIfCheck) Name() string { return "orphan-includeif" }
+
+func (orphanIncludeIfCheck) Run(env *Env) ([]Finding, error) {
+	orphans, err := env.Git.OrphanIncludeIfs(env.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, include := range orphans {
+		findings = append(findings, Finding{
+			Severity:       Warning,
+			Message:        fmt.Sprintf("includeIf %s (profile '%s') is not accounted for by any rule", include.Condition, include.Profile),
+			FixDescription: "remove the orphaned includeIf directive",
+			Fix: func(p *plan.Plan) error {
+				return env.Git.PlanRemoveIncludeIf(p, include)
+			},
+		})
+	}
+	return findings, nil
+}
+
+// includeIfOrderCheck flags managed includeIf directives in an order that
+// makes git pick a different profile than the rules in config.json
+type includeIfOrderCheck struct{}
+
+func (includeIfOrderCheck) Name() string { return "includeif-order" }
+
+func (includeIfOrderCheck) Run(env *Env) ([]Finding, error) {
+	inversions, err := env.Git.IncludeIfInversions(env.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, inversion := range inversions {
+		findings = append(findings, Finding{
+			Severity: Error,
+			Message: fmt.Sprintf("includeIf for %s (profile '%s') comes after the one for %s (profile '%s'), so git prefers it where both match",
+				inversion.Later.Rule, inversion.Later.Profile, inversion.Earlier.Rule, inversion.Earlier.Profile),
+			FixDescription: "reorder the managed includeIf directives",
+			Fix: func(p *plan.Plan) error {
+				return env.Git.PlanOrderIncludeIfs(p, env.Config)
+			},
+		})
+	}
+	return findings, nil
+}
+
+// sshEntryCheck flags profile hosts without a Host block in ~/.ssh/config
+type sshEntryCheck struct{}
+
+func (sshEntryCheck) Name() string { return "ssh-entries" }
+
+func (sshEntryCheck) Run(env *Env) ([]Finding, error) {
+	var findings []Finding
+	for _, profile := range sortedProfiles(env.Config) {
+		missing, err := env.SSH.MissingHostEntries(profile)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(missing) == 0 {
+			continue
+		}
+
+		// One finding per profile: a single fix writes every missing entry
+		aliases := make([]string, len(missing))
+		for i, host := range missing {
+			aliases[i] = host.HostAlias(profile.Name)
+		}
+		message := fmt.Sprintf("Profile '%s' has no Host %s entry in ~/.ssh/config", profile.Name, aliases[0])
+		if len(aliases) > 1 {
+			message = fmt.Sprintf("Profile '%s' has no Host entries for %s in ~/.ssh/config", profile.Name, strings.Join(aliases, ", "))
+		}
+
+		findings = append(findings, Finding{
+			Severity:       Error,
+			Message:        message,
+			FixDescription: "add the SSH config entries",
+			Fix: func(p *plan.Plan) error {
+				return env.SSH.PlanEnsureProfileEntry(p, profile)
+			},
+		})
+	}
+	return findings, nil
+}
+
+// sshKeyCheck flags missing private keys and keys readable by other users
+type sshKeyCheck struct{}
+
+func (sshKeyCheck) Name() string { return "ssh-keys" }
+
+func (sshKeyCheck) Run(env *Env) ([]Finding, error) {
+	var findings []Finding
+	for _, profile := range sortedProfiles(env.Config) {
+		keyPath := ssh.KeyPath(profile)
+
+		info, err := os.Stat(keyPath)
+		if os.IsNotExist(err) {
+			findings = append(findings, Finding{
+				Severity: Warning,
+				Message:  fmt.Sprintf("SSH key for profile '%s' not found: %s", profile.Name, keyPath),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", keyPath, err)
+		}
+
+		// Permission bits are not meaningful on Windows
+		if runtime.GOOS == "windows" || info.Mode().Perm()&0077 == 0 {
+			continue
+		}
+
+		path := keyPath
+		findings = append(findings, Finding{
+			Severity:       Error,
+			Message:        fmt.Sprintf("SSH key %s has permissions %o; ssh refuses keys readable by others", keyPath, info.Mode().Perm()),
+			FixDescription: "chmod 600",
+			Fix: func(p *plan.Plan) error {
+				return p.Chmod(path, 0600)
+			},
+		})
+	}
+	return findings, nil
+}
+
+// signingKeyCheck flags signing keys that cannot be used: OpenPGP keys that
+// are missing, expired, revoked or lack a matching user ID, and SSH signing
+// key files that do not exist
+type signingKeyCheck struct{}
+
+func (signingKeyCheck) Name() string { return "signing-keys" }
+
+func (signingKeyCheck) Run(env *Env) ([]Finding, error) {
+	var findings []Finding
+	var openpgp []*config.Profile
+	for _, profile := range sortedProfiles(env.Config) {
+		if profile.Signing == nil {
+			continue
+		}
+
+		switch profile.Signing.Format {
+		case config.SigningFormatOpenPGP:
+			openpgp = append(openpgp, profile)
+		case config.SigningFormatSSH:
+			keyPath := expandHome(profile.Signing.Key)
+			if strings.HasPrefix(keyPath, "key::") || strings.HasPrefix(keyPath, "ssh-") {
+				continue // Literal public key
+			}
+			if _, err := os.Stat(keyPath); os.IsNotExist(err) {
+				findings = append(findings, Finding{
+					Severity: Error,
+					Message:  fmt.Sprintf("SSH signing key for profile '%s' not found: %s", profile.Name, keyPath),
+				})
+			}
+		}
+	}
+	if len(openpgp) == 0 {
+		return findings, nil
+	}
+
+	if !gpg.Available() {
+		return append(findings, Finding{
+			Severity: Warning,
+			Message:  "gpg is not installed; GPG signing keys cannot be verified",
+		}), nil
+	}
+
+	keys, err := gpg.ListSecretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, profile := range openpgp {
+		for _, issue := range gpg.CheckSigningKey(keys, profile.Signing.Key, profile.Emails, now) {
+			severity := Warning
+			if issue.Blocking {
+				severity = Error
+			}
+			findings = append(findings, Finding{
+				Severity: severity,
+				Message:  fmt.Sprintf("Profile '%s': %s", profile.Name, issue.Message),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// expandHome expands a leading ~/ the way git does for user.signingkey paths
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[2:])
+}
@@ -0,0 +1,97 @@
+package doctor
+
+import (
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+	"github.com/calghar/gh-account-switcher/internal/ssh"
+)
+
+// Severity ranks how serious a finding is
+type Severity int
+
+const (
+	// Info findings are purely informational
+	Info Severity = iota
+	// Warning findings may cause surprising behaviour
+	Warning
+	// Error findings mean the derived state is broken
+	Error
+)
+
+// String returns the display name of a severity
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// Finding is a single problem reported by a check
+type Finding struct {
+	Check    string
+	Severity Severity
+	Message  string
+
+	// FixDescription summarizes what Fix would do
+	FixDescription string
+	// Fix schedules a repair through the git/ssh/config managers; nil when
+	// the problem cannot be repaired automatically
+	Fix func(p *plan.Plan) error
+}
+
+// Fixable reports whether the finding can be repaired automatically
+func (f Finding) Fixable() bool {
+	return f.Fix != nil
+}
+
+// Env is the state checks inspect
+type Env struct {
+	Config *config.Config
+	Git    *git.ConfigManager
+	SSH    *ssh.ConfigManager
+}
+
+// Check is a single diagnosis comparing derived state against config.json
+type Check interface {
+	// Name is a short identifier shown next to each finding
+	Name() string
+	// Run inspects the environment and reports any findings
+	Run(env *Env) ([]Finding, error)
+}
+
+// Result is the outcome of running one check
+type Result struct {
+	Check    string
+	Findings []Finding
+	Err      error
+}
+
+// Run executes every check in order. A check that fails to run is reported
+// in its Result rather than aborting the remaining checks.
+func Run(env *Env, checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		findings, err := check.Run(env)
+		for i := range findings {
+			findings[i].Check = check.Name()
+		}
+		results = append(results, Result{Check: check.Name(), Findings: findings, Err: err})
+	}
+	return results
+}
+
+// BySeverity groups all findings from results, most severe first
+func BySeverity(results []Result) map[Severity][]Finding {
+	grouped := make(map[Severity][]Finding)
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			grouped[finding.Severity] = append(grouped[finding.Severity], finding)
+		}
+	}
+	return grouped
+}
@@ -0,0 +1,92 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile atomically replaces the contents of path with data.
+//
+// The data is written to a temporary file in the same directory, synced to
+// disk and then renamed over the target, so readers only ever observe the old
+// or the new contents. Symlinks are resolved first so that dotfiles managed
+// through links keep pointing at the real file. If the target already exists
+// its permissions are preserved, otherwise perm is used.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	target, err := resolveTarget(path)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(target)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	// Clean up the temporary file on any failure path
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpName, target); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+	committed = true
+
+	syncDir(dir)
+	return nil
+}
+
+// resolveTarget follows symlinks so the rename replaces the real file
+func resolveTarget(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	if os.IsNotExist(err) {
+		// Dangling link or missing file: follow a single link level if present
+		if link, lerr := os.Readlink(path); lerr == nil {
+			if !filepath.IsAbs(link) {
+				link = filepath.Join(filepath.Dir(path), link)
+			}
+			return link, nil
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+}
+
+// syncDir flushes directory metadata so the rename survives a crash.
+// Errors are ignored because not every platform supports syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	d.Close()
+}
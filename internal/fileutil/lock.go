@@ -0,0 +1,79 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileLock is a cross-process advisory lock backed by a lock file.
+//
+// Locks are reentrant within a process: acquiring a path that this process
+// already holds only increments a counter, so nested load-modify-save cycles
+// in the same command never deadlock against themselves.
+type FileLock struct {
+	path string
+}
+
+type heldLock struct {
+	file  *os.File
+	count int
+}
+
+var (
+	locksMu sync.Mutex
+	held    = map[string]*heldLock{}
+)
+
+// Lock blocks until the advisory lock at path is acquired
+func Lock(path string) (*FileLock, error) {
+	locksMu.Lock()
+	defer locksMu.Unlock()
+
+	if h, ok := held[path]; ok {
+		h.count++
+		return &FileLock{path: path}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", path, err)
+	}
+
+	held[path] = &heldLock{file: file, count: 1}
+	return &FileLock{path: path}, nil
+}
+
+// Unlock releases one level of the lock; the file lock itself is dropped
+// once every nested holder has released it
+func (l *FileLock) Unlock() error {
+	locksMu.Lock()
+	defer locksMu.Unlock()
+
+	h, ok := held[l.path]
+	if !ok {
+		return fmt.Errorf("lock %s is not held", l.path)
+	}
+
+	h.count--
+	if h.count > 0 {
+		return nil
+	}
+
+	delete(held, l.path)
+	if err := unlockFile(h.file); err != nil {
+		h.file.Close()
+		return fmt.Errorf("failed to release lock %s: %w", l.path, err)
+	}
+	return h.file.Close()
+}
@@ -5,9 +5,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/gitconfig"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 )
 
 // ConfigManager manages Git configuration
@@ -27,166 +31,892 @@ func NewConfigManager() (*ConfigManager, error) {
 	}, nil
 }
 
-// SetupProfile creates a profile-specific gitconfig file and sets up includeIf
-func (gm *ConfigManager) SetupProfile(profile *config.Profile, directoryPath string) error {
-	// Create profile-specific gitconfig file
-	profileConfigPath := filepath.Join(gm.homeDir, fmt.Sprintf(".gitconfig-%s", profile.Name))
+// GlobalConfigPath returns the path of the user's global gitconfig, using the
+// same lookup as `git config --global`
+func (gm *ConfigManager) GlobalConfigPath() string {
+	if path := os.Getenv("GIT_CONFIG_GLOBAL"); path != "" {
+		return path
+	}
+
+	homeConfig := filepath.Join(gm.homeDir, ".gitconfig")
+	if _, err := os.Stat(homeConfig); err == nil {
+		return homeConfig
+	}
+
+	// Git writes to the XDG location only when it exists and ~/.gitconfig doesn't
+	xdgHome := os.Getenv("XDG_CONFIG_HOME")
+	if xdgHome == "" {
+		xdgHome = filepath.Join(gm.homeDir, ".config")
+	}
+	xdgConfig := filepath.Join(xdgHome, "git", "config")
+	if _, err := os.Stat(xdgConfig); err == nil {
+		return xdgConfig
+	}
+
+	return homeConfig
+}
+
+// ProfileConfigPath returns the path of a profile's generated gitconfig
+func (gm *ConfigManager) ProfileConfigPath(profileName string) string {
+	return filepath.Join(gm.homeDir, fmt.Sprintf(".gitconfig-%s", profileName))
+}
+
+// SetupProfile creates a profile-specific gitconfig file and sets up the
+// includeIf directives for a directory rule
+func (gm *ConfigManager) SetupProfile(profile *config.Profile, rule config.DirectoryRule) error {
+	unlock, err := config.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p := plan.New()
+	if err := gm.PlanSetupDirectoryRule(p, profile, rule); err != nil {
+		return err
+	}
+	return p.Apply()
+}
+
+// PlanSetupProfile schedules the profile-specific gitconfig file as part of
+// a plan
+func (gm *ConfigManager) PlanSetupProfile(p *plan.Plan, profile *config.Profile) error {
+	profileConfigPath := gm.ProfileConfigPath(profile.Name)
+
+	content, err := renderProfileConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	if err := p.WriteFile(profileConfigPath, content, 0600); err != nil {
+		return fmt.Errorf("failed to plan profile config: %w", err)
+	}
+
+	return nil
+}
+
+// PlanSetupDirectoryRule schedules the profile-specific gitconfig file and
+// the includeIf directives for a directory rule
+func (gm *ConfigManager) PlanSetupDirectoryRule(p *plan.Plan, profile *config.Profile, rule config.DirectoryRule) error {
+	if err := gm.PlanSetupProfile(p, profile); err != nil {
+		return err
+	}
+
+	for _, condition := range GitDirConditions(rule) {
+		if err := gm.planIncludeIf(p, profile, condition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PlanSetupRemoteRule schedules the profile-specific gitconfig file and the
+// includeIf directive for a remote rule
+func (gm *ConfigManager) PlanSetupRemoteRule(p *plan.Plan, profile *config.Profile, pattern string) error {
+	return gm.PlanSetupInclude(p, profile, RemoteCondition(pattern))
+}
 
-	var configContent strings.Builder
-	configContent.WriteString(fmt.Sprintf("# Git configuration for profile: %s\n", profile.Name))
-	configContent.WriteString(fmt.Sprintf("[user]\n"))
-	configContent.WriteString(fmt.Sprintf("\temail = %s\n", profile.PrimaryEmail))
+// PlanSetupInclude schedules the profile-specific gitconfig file and an
+// includeIf directive with the given condition
+func (gm *ConfigManager) PlanSetupInclude(p *plan.Plan, profile *config.Profile, condition string) error {
+	if err := gm.PlanSetupProfile(p, profile); err != nil {
+		return err
+	}
+	return gm.planIncludeIf(p, profile, condition)
+}
+
+// planIncludeIf schedules a managed includeIf directive loading the profile's
+// gitconfig under condition. An existing managed section for the condition is
+// reused; user-authored sections with the same condition are left alone. New
+// directives are appended; PlanOrderIncludeIfs moves them into place.
+func (gm *ConfigManager) planIncludeIf(p *plan.Plan, profile *config.Profile, condition string) error {
+	known := onlyProfile(profile.Name)
+	return gm.planEditGlobal(p, func(f *gitconfig.File) error {
+		var section *gitconfig.Section
+		for _, s := range f.Sections() {
+			if _, ok := gm.sectionProfile(s, known); ok && s.Subsection == condition {
+				section = s
+			}
+		}
+
+		// Keep a single managed section per condition
+		f.RemoveSections(func(s *gitconfig.Section) bool {
+			_, ok := gm.sectionProfile(s, known)
+			return ok && s.Subsection == condition && s != section
+		})
+
+		if section == nil {
+			section = f.AddSection("includeIf", condition)
+		}
+		if err := section.Set("path", gm.ProfileConfigPath(profile.Name)); err != nil {
+			return err
+		}
+		// Tag the directive so it can be told apart from user-authored ones
+		return section.Set(managedTagKey, profile.Name)
+	})
+}
+
+// ProfileValues returns the single-valued settings a profile's generated
+// gitconfig sets: identity, signing and SSH command
+func ProfileValues(profile *config.Profile) [][2]string {
+	values := [][2]string{{"user.email", profile.PrimaryEmail}}
 
 	if profile.GitName != "" {
-		configContent.WriteString(fmt.Sprintf("\tname = %s\n", profile.GitName))
+		values = append(values, [2]string{"user.name", profile.GitName})
 	}
 
-	// GPG signing configuration
-	if profile.GPGKey != "" {
-		configContent.WriteString(fmt.Sprintf("\tsigningkey = %s\n", profile.GPGKey))
-		configContent.WriteString(fmt.Sprintf("[commit]\n"))
-		configContent.WriteString(fmt.Sprintf("\tgpgsign = true\n"))
+	// Commit and tag signing configuration
+	if profile.Signing != nil {
+		values = append(values, profile.Signing.GitConfig()...)
 	}
 
-	// SSH command configuration (if SSH key path is specified)
-	if profile.SSHKeyPath != "" {
-		configContent.WriteString(fmt.Sprintf("[core]\n"))
-		configContent.WriteString(fmt.Sprintf("\tsshCommand = ssh -i %s -F /dev/null\n", profile.SSHKeyPath))
+	if command, ok := SSHCommand(profile); ok {
+		values = append(values, [2]string{"core.sshCommand", command})
 	}
 
-	// Write profile-specific config
-	if err := os.WriteFile(profileConfigPath, []byte(configContent.String()), 0600); err != nil {
-		return fmt.Errorf("failed to write profile config: %w", err)
+	return values
+}
+
+// SSHCommand returns the ssh command a profile's git config uses, if the
+// profile has an SSH key path. ssh_config is still read so the profile's
+// host aliases, used by URL rewrites, resolve. Git runs the command through
+// the shell, so the key path is quoted.
+func SSHCommand(profile *config.Profile) (string, bool) {
+	if profile.SSHKeyPath == "" {
+		return "", false
 	}
+	return fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes", shellQuote(profile.SSHKeyPath)), true
+}
 
-	// Add includeIf directive to global gitconfig
-	if directoryPath != "" {
-		absPath, err := filepath.Abs(directoryPath)
-		if err != nil {
-			return fmt.Errorf("failed to resolve absolute path: %w", err)
+// shellQuote quotes s for a POSIX shell unless it only has characters the
+// shell takes literally
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789/._+-@:,=%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// renderProfileConfig builds the contents of a profile's generated gitconfig
+func renderProfileConfig(profile *config.Profile) ([]byte, error) {
+	f := gitconfig.New()
+	f.AddComment(fmt.Sprintf("Git configuration for profile: %s", profile.Name))
+
+	for _, kv := range ProfileValues(profile) {
+		if err := f.Set(kv[0], kv[1]); err != nil {
+			return nil, fmt.Errorf("failed to render profile config: %w", err)
+		}
+	}
+
+	// URL rewrites and extra per-profile settings, which may be multi-valued
+	for _, entry := range profile.GeneratedGitConfig() {
+		if err := f.Add(entry.Key, entry.Value); err != nil {
+			return nil, fmt.Errorf("failed to render profile config: %w", err)
+		}
+	}
+
+	return f.Bytes(), nil
+}
+
+// managedTagKey marks includeIf sections created by gh-switch; its value is
+// the profile the directive belongs to
+const managedTagKey = "ghswitch"
+
+// IncludeIf is an includeIf directive found in the global gitconfig
+type IncludeIf struct {
+	Condition string
+	Path      string
+	// Profile is the gh-switch profile the directive belongs to, if managed
+	Profile string
+	// Managed reports whether gh-switch created the directive
+	Managed bool
+}
+
+// GitDirConditions returns the includeIf conditions that together match a
+// directory rule: one per gitdir pattern, case-insensitive (gitdir/i) if the
+// rule ignores case
+func GitDirConditions(rule config.DirectoryRule) []string {
+	var conditions []string
+	for _, pattern := range rule.GitDirPatterns() {
+		conditions = append(conditions, GitDirCondition(rule, pattern))
+	}
+	return conditions
+}
+
+// GitDirCondition returns the includeIf condition for one of a directory
+// rule's gitdir patterns
+func GitDirCondition(rule config.DirectoryRule, pattern config.GitDirPattern) string {
+	if rule.IgnoreCase {
+		return "gitdir/i:" + pattern.Pattern
+	}
+	return "gitdir:" + pattern.Pattern
+}
+
+// remoteConditionPrefix starts includeIf conditions matching remote URLs
+const remoteConditionPrefix = "hasconfig:remote.*.url:"
+
+// RemoteCondition returns the includeIf condition matching a remote rule
+func RemoteCondition(pattern string) string {
+	return remoteConditionPrefix + pattern
+}
+
+// RuleInclude is the includeIf directive a directory or remote rule needs
+type RuleInclude struct {
+	Condition string
+	Profile   string
+	// Rule describes the rule, e.g. "directory rule /home/me/work"
+	Rule string
+	// Directory is the directory rule, or nil for a remote rule
+	Directory *config.DirectoryRule
+}
+
+// RuleIncludes returns the includeIf directives the configuration's rules
+// need, skipping rules whose profile doesn't exist. They are in the order git
+// must evaluate them for its last match to be the rule the resolver picks:
+// directory rules from least to most specific, then remote rules, which take
+// precedence over directories.
+func RuleIncludes(cfg *config.Config) ([]RuleInclude, error) {
+	// Equally specific rules keep their order, since the later one wins
+	rules := make([]config.DirectoryRule, len(cfg.DirectoryRules))
+	copy(rules, cfg.DirectoryRules)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return config.CompareSpecificity(rules[i], rules[j]) < 0
+	})
+
+	var includes []RuleInclude
+	for i := range rules {
+		rule := &rules[i]
+		if _, err := cfg.GetProfile(rule.Profile); err != nil {
+			continue
+		}
+		for _, condition := range GitDirConditions(*rule) {
+			includes = append(includes, RuleInclude{
+				Condition: condition,
+				Profile:   rule.Profile,
+				Rule:      "directory rule " + rule.Path,
+				Directory: rule,
+			})
 		}
+	}
 
-		// Ensure path ends with /
-		if !strings.HasSuffix(absPath, "/") {
-			absPath += "/"
+	for _, rule := range cfg.RemoteRules {
+		if _, err := cfg.GetProfile(rule.Profile); err != nil {
+			continue
 		}
+		includes = append(includes, RuleInclude{
+			Condition: RemoteCondition(rule.Pattern),
+			Profile:   rule.Profile,
+			Rule:      "remote rule " + rule.Pattern,
+		})
+	}
+
+	return includes, nil
+}
 
-		// Check if includeIf already exists
-		includeIfSection := fmt.Sprintf("includeIf.gitdir:%s.path", absPath)
-		existingPath := gm.getGlobalConfig(includeIfSection)
+// ListIncludeIfs returns every includeIf directive in the global gitconfig,
+// one per section. Untagged sections are recognized as managed only for the
+// profiles in cfg.
+func (gm *ConfigManager) ListIncludeIfs(cfg *config.Config) ([]IncludeIf, error) {
+	f, err := gitconfig.Load(gm.GlobalConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	return gm.includeIfs(f, configProfiles(cfg)), nil
+}
 
-		if existingPath == "" {
-			// Add new includeIf directive
-			if err := gm.setGlobalConfig(includeIfSection, profileConfigPath); err != nil {
-				return fmt.Errorf("failed to set includeIf directive: %w", err)
+// includeIfs extracts the includeIf directives from a parsed gitconfig
+func (gm *ConfigManager) includeIfs(f *gitconfig.File, known func(string) bool) []IncludeIf {
+	var includes []IncludeIf
+	for _, section := range f.Sections() {
+		if !strings.EqualFold(section.Name, "includeIf") || section.Subsection == "" {
+			continue
+		}
+
+		include := IncludeIf{Condition: section.Subsection}
+		for _, entry := range section.Entries() {
+			if strings.EqualFold(entry.Key, "path") {
+				include.Path = entry.Value
 			}
-		} else if existingPath != profileConfigPath {
-			// Update existing includeIf directive
-			if err := gm.setGlobalConfig(includeIfSection, profileConfigPath); err != nil {
-				return fmt.Errorf("failed to update includeIf directive: %w", err)
+		}
+		include.Profile, include.Managed = gm.sectionProfile(section, known)
+
+		if include.Path != "" || include.Managed {
+			includes = append(includes, include)
+		}
+	}
+	return includes
+}
+
+// sectionProfile returns the profile an includeIf section was created for,
+// if gh-switch created it. Sections are identified by their ghswitch tag;
+// untagged ones written before tagging are recognized only if their sole key
+// is a path to the generated gitconfig of a profile known reports.
+func (gm *ConfigManager) sectionProfile(section *gitconfig.Section, known func(string) bool) (string, bool) {
+	if !strings.EqualFold(section.Name, "includeIf") || section.Subsection == "" {
+		return "", false
+	}
+
+	entries := section.Entries()
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Key, managedTagKey) {
+			return entry.Value, true
+		}
+	}
+
+	if len(entries) != 1 || !strings.EqualFold(entries[0].Key, "path") {
+		return "", false
+	}
+	return gm.profileForConfigPath(entries[0].Value, known)
+}
+
+// ProfileForConfigPath returns the profile in cfg whose generated gitconfig
+// is path
+func (gm *ConfigManager) ProfileForConfigPath(cfg *config.Config, path string) (string, bool) {
+	return gm.profileForConfigPath(path, configProfiles(cfg))
+}
+
+func (gm *ConfigManager) profileForConfigPath(path string, known func(string) bool) (string, bool) {
+	prefix := gm.ProfileConfigPath("")
+	name := strings.TrimPrefix(path, prefix)
+	if len(name) == len(path) || name == "" || !known(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// configProfiles reports whether a name is one of cfg's profiles
+func configProfiles(cfg *config.Config) func(string) bool {
+	return func(name string) bool {
+		_, err := cfg.GetProfile(name)
+		return err == nil
+	}
+}
+
+// onlyProfile reports whether a name is profileName
+func onlyProfile(profileName string) func(string) bool {
+	return func(name string) bool {
+		return name == profileName
+	}
+}
+
+// ManagedIncludeIfs returns the includeIf directives gh-switch created
+func (gm *ConfigManager) ManagedIncludeIfs(cfg *config.Config) ([]IncludeIf, error) {
+	includes, err := gm.ListIncludeIfs(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var managed []IncludeIf
+	for _, include := range includes {
+		if include.Managed {
+			managed = append(managed, include)
+		}
+	}
+	return managed, nil
+}
+
+// OrphanIncludeIfs returns managed includeIf directives that no directory or
+// remote rule in the configuration accounts for
+func (gm *ConfigManager) OrphanIncludeIfs(cfg *config.Config) ([]IncludeIf, error) {
+	managed, err := gm.ManagedIncludeIfs(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := RuleIncludes(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[string]string)
+	for _, rule := range rules {
+		expected[rule.Condition] = rule.Profile
+	}
+
+	var orphans []IncludeIf
+	for _, include := range managed {
+		if profile, ok := expected[include.Condition]; !ok || profile != include.Profile {
+			orphans = append(orphans, include)
+		}
+	}
+	return orphans, nil
+}
+
+// includeRanks maps each rule's includeIf condition to its position in the
+// order RuleIncludes prescribes
+func includeRanks(cfg *config.Config) (map[string]int, []RuleInclude, error) {
+	rules, err := RuleIncludes(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ranks := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		ranks[rule.Condition] = i
+	}
+	return ranks, rules, nil
+}
+
+// PlanOrderIncludeIfs schedules sorting the managed includeIf sections into
+// the order RuleIncludes prescribes, so git's last match agrees with the
+// resolver. The sections only swap the slots they already occupy, leaving
+// user-authored sections in place; directives no rule accounts for go first,
+// where they are overridden by every rule.
+func (gm *ConfigManager) PlanOrderIncludeIfs(p *plan.Plan, cfg *config.Config) error {
+	ranks, _, err := includeRanks(cfg)
+	if err != nil {
+		return err
+	}
+
+	rank := func(section *gitconfig.Section) int {
+		if i, ok := ranks[section.Subsection]; ok {
+			return i
+		}
+		return -1
+	}
+
+	known := configProfiles(cfg)
+	managed := func(section *gitconfig.Section) bool {
+		_, ok := gm.sectionProfile(section, known)
+		return ok
+	}
+
+	return gm.planEditGlobal(p, func(f *gitconfig.File) error {
+		f.SortSections(managed, func(a, b *gitconfig.Section) bool {
+			return rank(a) < rank(b)
+		})
+		return nil
+	})
+}
+
+// IncludeIfInversion is a pair of managed includeIf directives for different
+// profiles that git evaluates in the wrong order: Later follows Earlier in
+// the global gitconfig, so git lets it win although the resolver prefers
+// Earlier
+type IncludeIfInversion struct {
+	Earlier, Later RuleInclude
+}
+
+// IncludeIfInversions returns the managed includeIf directives whose order in
+// the global gitconfig disagrees with the configuration's rules
+func (gm *ConfigManager) IncludeIfInversions(cfg *config.Config) ([]IncludeIfInversion, error) {
+	f, err := gitconfig.Load(gm.GlobalConfigPath())
+	if err != nil {
+		return nil, err
+	}
+
+	ranks, rules, err := includeRanks(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// A directive repeated across sections takes effect at its last one,
+	// which is where git loads its path last
+	known := configProfiles(cfg)
+	last := make(map[string]int)
+	for i, section := range f.Sections() {
+		if _, ok := ranks[section.Subsection]; !ok {
+			continue
+		}
+		if _, ok := gm.sectionProfile(section, known); ok {
+			last[section.Subsection] = i
+		}
+	}
+
+	conditions := make([]string, 0, len(last))
+	for condition := range last {
+		conditions = append(conditions, condition)
+	}
+	sort.Slice(conditions, func(i, j int) bool {
+		return last[conditions[i]] < last[conditions[j]]
+	})
+
+	order := make([]int, len(conditions))
+	for i, condition := range conditions {
+		order[i] = ranks[condition]
+	}
+
+	// Report each pair of rules once, however many conditions they have
+	var inversions []IncludeIfInversion
+	seen := make(map[[2]string]bool)
+	for i := range order {
+		for j := i + 1; j < len(order); j++ {
+			if order[i] < order[j] {
+				continue
+			}
+			earlier, later := rules[order[i]], rules[order[j]]
+			if earlier.Profile == later.Profile || seen[[2]string{earlier.Rule, later.Rule}] {
+				continue
 			}
+			// Order only matters where both rules can match
+			if earlier.Directory != nil && later.Directory != nil && !earlier.Directory.Overlaps(*later.Directory) {
+				continue
+			}
+			seen[[2]string{earlier.Rule, later.Rule}] = true
+			inversions = append(inversions, IncludeIfInversion{Earlier: earlier, Later: later})
 		}
 	}
+	return inversions, nil
+}
+
+// PlanRemoveIncludeIf schedules removal of a managed includeIf directive:
+// the sections with its condition that belong to its profile
+func (gm *ConfigManager) PlanRemoveIncludeIf(p *plan.Plan, include IncludeIf) error {
+	return gm.planRemoveIncludeIfs(p, func(condition, profile string) bool {
+		return condition == include.Condition && profile == include.Profile
+	}, onlyProfile(include.Profile))
+}
 
+// PlanRemoveDirectoryIncludeIf schedules removal of the managed includeIf
+// directives for a directory rule
+func (gm *ConfigManager) PlanRemoveDirectoryIncludeIf(p *plan.Plan, rule config.DirectoryRule) error {
+	for _, condition := range GitDirConditions(rule) {
+		if err := gm.PlanRemoveManagedIncludeIf(p, condition, rule.Profile); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// SetupAllProfiles sets up includeIf directives for all directory rules
+// PlanRemoveManagedIncludeIf schedules removal of the managed includeIf
+// directives with condition, if there are any. Untagged sections are
+// recognized only for profileName.
+func (gm *ConfigManager) PlanRemoveManagedIncludeIf(p *plan.Plan, condition, profileName string) error {
+	return gm.planRemoveIncludeIfs(p, func(c, _ string) bool {
+		return c == condition
+	}, onlyProfile(profileName))
+}
+
+// planRemoveIncludeIfs schedules removal of the managed includeIf sections
+// selected by match, which is given each section's condition and profile
+func (gm *ConfigManager) planRemoveIncludeIfs(p *plan.Plan, match func(condition, profile string) bool, known func(string) bool) error {
+	return gm.planEditGlobal(p, func(f *gitconfig.File) error {
+		f.RemoveSections(func(section *gitconfig.Section) bool {
+			profile, ok := gm.sectionProfile(section, known)
+			return ok && match(section.Subsection, profile)
+		})
+		return nil
+	})
+}
+
+// SetupAllProfiles sets up includeIf directives for all directory and remote rules
 func (gm *ConfigManager) SetupAllProfiles(cfg *config.Config) error {
+	unlock, err := config.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p := plan.New()
 	for _, rule := range cfg.DirectoryRules {
 		profile, err := cfg.GetProfile(rule.Profile)
 		if err != nil {
 			return fmt.Errorf("failed to get profile %s: %w", rule.Profile, err)
 		}
 
-		if err := gm.SetupProfile(profile, rule.Path); err != nil {
+		if err := gm.PlanSetupDirectoryRule(p, profile, rule); err != nil {
 			return fmt.Errorf("failed to setup profile %s: %w", profile.Name, err)
 		}
 	}
 
-	return nil
+	for _, rule := range cfg.RemoteRules {
+		profile, err := cfg.GetProfile(rule.Profile)
+		if err != nil {
+			return fmt.Errorf("failed to get profile %s: %w", rule.Profile, err)
+		}
+
+		if err := gm.PlanSetupRemoteRule(p, profile, rule.Pattern); err != nil {
+			return fmt.Errorf("failed to setup profile %s: %w", profile.Name, err)
+		}
+	}
+
+	if err := gm.PlanOrderIncludeIfs(p, cfg); err != nil {
+		return err
+	}
+
+	return p.Apply()
 }
 
-// SwitchProfile manually switches to a profile globally (for non-directory-based switching)
-func (gm *ConfigManager) SwitchProfile(profile *config.Profile) error {
-	// Set global user.email
-	if err := gm.setGlobalConfig("user.email", profile.PrimaryEmail); err != nil {
-		return fmt.Errorf("failed to set email: %w", err)
+// SwitchProfile manually switches to a profile globally (for non-directory-based
+// switching). previous is the profile switched away from, if any.
+func (gm *ConfigManager) SwitchProfile(profile, previous *config.Profile) error {
+	unlock, err := config.Lock()
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	// Set global user.name if specified
-	if profile.GitName != "" {
-		if err := gm.setGlobalConfig("user.name", profile.GitName); err != nil {
-			return fmt.Errorf("failed to set name: %w", err)
+	p := plan.New()
+	if err := gm.PlanSwitchProfile(p, profile, previous); err != nil {
+		return err
+	}
+	return p.Apply()
+}
+
+// PlanSwitchProfile schedules the global identity changes for a manual switch.
+// Extra settings of the previous profile that the new one does not set are
+// removed, unless they were changed since.
+func (gm *ConfigManager) PlanSwitchProfile(p *plan.Plan, profile, previous *config.Profile) error {
+	return gm.planEditGlobal(p, func(f *gitconfig.File) error {
+		if err := switchGitConfig(f, profile, previous); err != nil {
+			return err
+		}
+
+		// Global identity must come before the includeIf sections, otherwise
+		// it would override the directory-based profiles
+		ensureSectionBeforeIncludes(f, "user", "")
+
+		// Set global user.email
+		if err := f.Set("user.email", profile.PrimaryEmail); err != nil {
+			return err
 		}
+
+		// Set global user.name if specified
+		if profile.GitName != "" {
+			if err := f.Set("user.name", profile.GitName); err != nil {
+				return err
+			}
+		}
+
+		// Commit and tag signing
+		if profile.Signing != nil {
+			for _, kv := range profile.Signing.GitConfig() {
+				section, subsection, _, err := gitconfig.SplitKey(kv[0])
+				if err != nil {
+					return err
+				}
+				ensureSectionBeforeIncludes(f, section, subsection)
+				if err := f.Set(kv[0], kv[1]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		// The signing program is left alone: it may be the user's own setting
+		for _, key := range []string{"commit.gpgsign", "tag.gpgsign", "user.signingkey", "gpg.format"} {
+			if _, err := f.Unset(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// switchGitConfig replaces the previous profile's extra settings in the
+// global config with the new profile's. A core.hooksPath pointing at
+// gh-switch's hooks is left alone.
+func switchGitConfig(f *gitconfig.File, profile, previous *config.Profile) error {
+	hooksPath, ok := f.Get("core.hooksPath")
+	keep := func(key string) bool {
+		canonical, err := gitconfig.CanonicalKey(key)
+		return err == nil && canonical == "core.hookspath" && ok && OwnsHooksPath(hooksPath)
 	}
 
-	// GPG signing
-	if profile.GPGKey != "" {
-		if err := gm.setGlobalConfig("user.signingkey", profile.GPGKey); err != nil {
-			return fmt.Errorf("failed to set GPG key: %w", err)
+	if previous != nil {
+		entries := profile.GeneratedGitConfig()
+		previousEntries := previous.GeneratedGitConfig()
+		for _, entry := range previousEntries {
+			if len(entryValues(entries, entry.Key)) > 0 || keep(entry.Key) {
+				continue
+			}
+			if slices.Equal(f.GetAll(entry.Key), entryValues(previousEntries, entry.Key)) {
+				if _, err := f.Unset(entry.Key); err != nil {
+					return err
+				}
+			}
 		}
-		if err := gm.setGlobalConfig("commit.gpgsign", "true"); err != nil {
-			return fmt.Errorf("failed to enable GPG signing: %w", err)
+	}
+
+	set := make(map[string]bool)
+	for _, entry := range profile.GeneratedGitConfig() {
+		if keep(entry.Key) {
+			continue
+		}
+		section, subsection, _, err := gitconfig.SplitKey(entry.Key)
+		if err != nil {
+			return err
+		}
+		canonical, err := gitconfig.CanonicalKey(entry.Key)
+		if err != nil {
+			return err
+		}
+
+		// The first value replaces whatever the global config had
+		if !set[canonical] {
+			set[canonical] = true
+			if _, err := f.Unset(entry.Key); err != nil {
+				return err
+			}
+			ensureSectionBeforeIncludes(f, section, subsection)
+		}
+		if err := f.Add(entry.Key, entry.Value); err != nil {
+			return err
 		}
-	} else {
-		// Optionally unset GPG signing
-		_ = gm.unsetGlobalConfig("commit.gpgsign")
-		_ = gm.unsetGlobalConfig("user.signingkey")
 	}
 
 	return nil
 }
 
-// GetCurrentConfig returns the current git configuration
-func (gm *ConfigManager) GetCurrentConfig() (email, name, signingKey string, gpgEnabled bool) {
-	email = gm.getGlobalConfig("user.email")
-	name = gm.getGlobalConfig("user.name")
-	signingKey = gm.getGlobalConfig("user.signingkey")
-	gpgSign := gm.getGlobalConfig("commit.gpgsign")
-	gpgEnabled = strings.ToLower(gpgSign) == "true"
+// entryValues returns every value of a key among entries
+func entryValues(entries []config.GitConfigEntry, key string) []string {
+	canonical, err := gitconfig.CanonicalKey(key)
+	if err != nil {
+		return nil
+	}
+	var values []string
+	for _, entry := range entries {
+		if other, err := gitconfig.CanonicalKey(entry.Key); err == nil && other == canonical {
+			values = append(values, entry.Value)
+		}
+	}
+	return values
+}
+
+// ensureSectionBeforeIncludes creates an empty section ahead of the first
+// includeIf section if the file has no such section yet
+func ensureSectionBeforeIncludes(f *gitconfig.File, name, subsection string) {
+	sections := f.Sections()
+	for _, section := range sections {
+		if section.Is(name, subsection) {
+			return
+		}
+	}
+
+	for i, section := range sections {
+		if strings.EqualFold(section.Name, "includeIf") {
+			f.InsertSection(i, name, subsection)
+			return
+		}
+	}
+}
 
-	return
+// Identity is the global git identity and signing setup
+type Identity struct {
+	Email         string
+	Name          string
+	SigningKey    string
+	SigningFormat string
+	SignCommits   bool
+	SignTags      bool
 }
 
-// setGlobalConfig sets a global git configuration value
-func (gm *ConfigManager) setGlobalConfig(key, value string) error {
-	cmd := exec.Command("git", "config", "--global", key, value)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git config failed: %w\n%s", err, string(output))
+// GetCurrentConfig returns the current global git identity
+func (gm *ConfigManager) GetCurrentConfig() (*Identity, error) {
+	f, err := gitconfig.Load(gm.GlobalConfigPath())
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	identity := &Identity{SigningFormat: config.SigningFormatOpenPGP}
+	identity.Email, _ = f.Get("user.email")
+	identity.Name, _ = f.Get("user.name")
+	identity.SigningKey, _ = f.Get("user.signingkey")
+	if format, ok := f.Get("gpg.format"); ok {
+		identity.SigningFormat = format
+	}
+
+	if identity.SignCommits, err = f.GetBool("commit.gpgsign"); err != nil {
+		return nil, fmt.Errorf("invalid commit.gpgsign: %w", err)
+	}
+	if identity.SignTags, err = f.GetBool("tag.gpgsign"); err != nil {
+		return nil, fmt.Errorf("invalid tag.gpgsign: %w", err)
+	}
+
+	return identity, nil
 }
 
-// unsetGlobalConfig unsets a global git configuration value
-func (gm *ConfigManager) unsetGlobalConfig(key string) error {
-	cmd := exec.Command("git", "config", "--global", "--unset", key)
-	_ = cmd.Run() // Ignore errors (key might not exist)
-	return nil
+// HooksPath returns the global core.hooksPath, if one is set
+func (gm *ConfigManager) HooksPath() (string, bool, error) {
+	f, err := gitconfig.Load(gm.GlobalConfigPath())
+	if err != nil {
+		return "", false, err
+	}
+	path, ok := f.Get("core.hooksPath")
+	return path, ok, nil
+}
+
+// HooksInstalled reports whether the global core.hooksPath points at
+// gh-switch's hooks directory
+func (gm *ConfigManager) HooksInstalled() (bool, error) {
+	path, ok, err := gm.HooksPath()
+	return ok && OwnsHooksPath(path), err
+}
+
+// OwnsHooksPath reports whether a core.hooksPath value names gh-switch's
+// hooks directory
+func OwnsHooksPath(value string) bool {
+	dir, err := config.HooksDir()
+	if err != nil {
+		return false
+	}
+	expanded, err := config.ExpandPath(value)
+	return err == nil && filepath.Clean(expanded) == filepath.Clean(dir)
+}
+
+// PlanSetHooksPath schedules pointing the global core.hooksPath at dir
+func (gm *ConfigManager) PlanSetHooksPath(p *plan.Plan, dir string) error {
+	return gm.planEditGlobal(p, func(f *gitconfig.File) error {
+		// Ahead of the includeIf sections, with the rest of the global settings
+		ensureSectionBeforeIncludes(f, "core", "")
+		return f.Set("core.hooksPath", dir)
+	})
+}
+
+// PlanUnsetHooksPath schedules removing the global core.hooksPath
+func (gm *ConfigManager) PlanUnsetHooksPath(p *plan.Plan) error {
+	return gm.planEditGlobal(p, func(f *gitconfig.File) error {
+		_, err := f.Unset("core.hooksPath")
+		return err
+	})
 }
 
-// getGlobalConfig gets a global git configuration value
-func (gm *ConfigManager) getGlobalConfig(key string) string {
-	cmd := exec.Command("git", "config", "--global", "--get", key)
-	output, err := cmd.Output()
+// planEditGlobal schedules an edit of the global gitconfig, starting from
+// any change already pending in the plan
+func (gm *ConfigManager) planEditGlobal(p *plan.Plan, edit func(f *gitconfig.File) error) error {
+	path := gm.GlobalConfigPath()
+
+	data, _, err := p.Contents(path)
 	if err != nil {
-		return ""
+		return err
+	}
+
+	f, err := gitconfig.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err := edit(f); err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
 	}
-	return strings.TrimSpace(string(output))
+
+	return p.WriteFile(path, f.Bytes(), 0644)
 }
 
 // RemoveProfileConfig removes a profile's gitconfig file and includeIf directives
 func (gm *ConfigManager) RemoveProfileConfig(profileName string) error {
-	// Remove profile-specific gitconfig file
-	profileConfigPath := filepath.Join(gm.homeDir, fmt.Sprintf(".gitconfig-%s", profileName))
-	if err := os.Remove(profileConfigPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove profile config: %w", err)
+	unlock, err := config.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p := plan.New()
+	if err := gm.PlanRemoveProfileConfig(p, profileName); err != nil {
+		return err
 	}
+	return p.Apply()
+}
 
-	// Note: includeIf directives remain in global config but point to non-existent files
-	// This is safe and won't cause issues
+// PlanRemoveProfileConfig schedules removal of a profile's gitconfig file
+// and every managed includeIf directive that belongs to the profile
+func (gm *ConfigManager) PlanRemoveProfileConfig(p *plan.Plan, profileName string) error {
+	if err := p.RemoveFile(gm.ProfileConfigPath(profileName)); err != nil {
+		return fmt.Errorf("failed to plan profile config removal: %w", err)
+	}
 
-	return nil
+	return gm.planRemoveIncludeIfs(p, func(_, profile string) bool {
+		return profile == profileName
+	}, onlyProfile(profileName))
 }
 
 // CheckGitInstalled verifies that git is installed
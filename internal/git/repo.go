@@ -0,0 +1,272 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/plan"
+)
+
+// ConfigValue is the effective value of a setting in a repository, with the
+// scope and origin git reports for it
+type ConfigValue struct {
+	Key   string
+	Value string
+	// Scope is git's config scope, such as "global" or "local"
+	Scope string
+	// Origin is where git read the value, such as "file:/home/me/.gitconfig"
+	Origin string
+	// Set reports whether the setting has a value at all
+	Set bool
+}
+
+// RepoGitDir returns the absolute .git directory of the repository
+// containing path
+func RepoGitDir(path string) (string, error) {
+	out, err := exec.Command("git", "-C", path, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("not inside a git repository: %s", path)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Remote is a URL configured for one of a repository's remotes
+type Remote struct {
+	Name string
+	URL  string
+}
+
+// Remotes returns the remote URLs configured for the repository containing
+// path, as written in its configuration. A remote with several URLs is
+// listed once per URL.
+func Remotes(path string) ([]Remote, error) {
+	out, err := exec.Command("git", "-C", path, "config", "--get-regexp", `^remote\..*\.url$`).Output()
+	if err != nil {
+		// Exit status 1 means there are no remotes
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read remotes: %w", err)
+	}
+
+	var remotes []Remote
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		key, url, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "remote."), ".url")
+		remotes = append(remotes, Remote{Name: name, URL: url})
+	}
+	return remotes, nil
+}
+
+// RemoteURLs returns the remote URLs configured for the repository
+// containing path
+func RemoteURLs(path string) ([]string, error) {
+	remotes, err := Remotes(path)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(remotes))
+	for _, remote := range remotes {
+		urls = append(urls, remote.URL)
+	}
+	return urls, nil
+}
+
+// PlanSetRemoteURL schedules replacing one URL of a repository's remote,
+// restoring it on rollback
+func PlanSetRemoteURL(p *plan.Plan, repo, remote, oldURL, newURL string) {
+	// The old URL selects which of several URLs to replace; git treats it as
+	// a regular expression
+	p.Run(
+		[]string{"git", "-C", repo, "remote", "set-url", remote, newURL, "^" + regexp.QuoteMeta(oldURL) + "$"},
+		[]string{"git", "-C", repo, "remote", "set-url", remote, oldURL, "^" + regexp.QuoteMeta(newURL) + "$"},
+	)
+}
+
+// FindRepositories returns the working trees of the git repositories at or
+// below root, including nested repositories and submodules. Directories
+// that cannot be read are skipped.
+func FindRepositories(root string) ([]string, error) {
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return fs.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return fs.SkipDir
+		}
+
+		// A .git directory, or a .git file for submodules and worktrees
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", root, err)
+	}
+	return repos, nil
+}
+
+// EffectiveConfig asks git for the effective value of each key in the
+// repository containing path, after all includes are applied
+func EffectiveConfig(path string, keys ...string) ([]ConfigValue, error) {
+	values := make([]ConfigValue, 0, len(keys))
+	for _, key := range keys {
+		value := ConfigValue{Key: key}
+
+		out, err := exec.Command("git", "-C", path, "config", "--show-scope", "--show-origin", "--get", key).Output()
+		if err != nil {
+			// Exit status 1 means the key is not set
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+				values = append(values, value)
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+
+		// Output is "<scope>\t<origin>\t<value>"
+		fields := strings.SplitN(strings.TrimSuffix(string(out), "\n"), "\t", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected git config output for %s: %q", key, out)
+		}
+		value.Scope, value.Origin, value.Value, value.Set = fields[0], fields[1], fields[2], true
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// WebFlowEmail is the committer of merges and edits made in GitHub's web
+// interface on behalf of the author
+const WebFlowEmail = "noreply@github.com"
+
+// Commit is a commit's identity and signature status as recorded by git log
+type Commit struct {
+	Hash           string
+	Date           string
+	AuthorEmail    string
+	CommitterEmail string
+	// Signature is git's %G? status: "N" for unsigned commits, otherwise a
+	// letter describing the signature
+	Signature string
+	Subject   string
+}
+
+// Signed reports whether the commit carries a signature, valid or not
+func (c Commit) Signed() bool {
+	return c.Signature != "" && c.Signature != "N"
+}
+
+// Identities returns the roles and emails to check for the commit: the
+// author, and the committer unless it is the author or GitHub's web flow
+func (c Commit) Identities() [][2]string {
+	identities := [][2]string{{"author", c.AuthorEmail}}
+	if !strings.EqualFold(c.CommitterEmail, c.AuthorEmail) && !strings.EqualFold(c.CommitterEmail, WebFlowEmail) {
+		identities = append(identities, [2]string{"committer", c.CommitterEmail})
+	}
+	return identities
+}
+
+// Commits returns the commits reachable from HEAD in the repository at path,
+// newest first. since and authors are passed to git log's --since and
+// --author when set.
+func Commits(path, since string, authors []string) ([]Commit, error) {
+	var args []string
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	for _, author := range authors {
+		args = append(args, "--author="+author)
+	}
+
+	commits, err := logCommits(path, args...)
+	if err != nil {
+		// A repository without commits has no HEAD to log
+		if _, headErr := exec.Command("git", "-C", path, "rev-parse", "--verify", "-q", "HEAD").Output(); headErr != nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return commits, nil
+}
+
+// CommitsInRange returns the commits selected by revs, such as "a..b" or
+// "b --not --remotes=origin", newest first
+func CommitsInRange(path string, revs ...string) ([]Commit, error) {
+	return logCommits(path, revs...)
+}
+
+// HasCommit reports whether the repository at path has the commit sha
+func HasCommit(path, sha string) bool {
+	return exec.Command("git", "-C", path, "cat-file", "-e", sha+"^{commit}").Run() == nil
+}
+
+// logCommits runs git log with args in the repository at path
+func logCommits(path string, args ...string) ([]Commit, error) {
+	// Fields are separated by unit separators and records by record separators,
+	// which cannot appear in emails and are vanishingly rare in subjects
+	args = append([]string{"-C", path, "log", "--format=%H%x1f%aI%x1f%ae%x1f%ce%x1f%G?%x1f%s%x1e"}, args...)
+
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", path, err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(string(out), "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) != 6 {
+			continue
+		}
+		commits = append(commits, Commit{
+			Hash:           fields[0],
+			Date:           fields[1],
+			AuthorEmail:    fields[2],
+			CommitterEmail: fields[3],
+			Signature:      fields[4],
+			Subject:        fields[5],
+		})
+	}
+	return commits, nil
+}
+
+// Ident returns the name and email git records for the author or committer
+// of a new commit in the repository at path. kind is "GIT_AUTHOR_IDENT" or
+// "GIT_COMMITTER_IDENT". Unlike user.email, this accounts for environment
+// overrides such as GIT_AUTHOR_EMAIL.
+func Ident(path, kind string) (string, string, error) {
+	out, err := exec.Command("git", "-C", path, "var", kind).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", "", fmt.Errorf("git has no identity: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", "", fmt.Errorf("failed to read %s: %w", kind, err)
+	}
+
+	// Name <email> timestamp timezone
+	ident := strings.TrimSpace(string(out))
+	start, end := strings.LastIndex(ident, "<"), strings.LastIndex(ident, ">")
+	if start < 0 || end < start {
+		return "", "", fmt.Errorf("unexpected %s: %s", kind, ident)
+	}
+	return strings.TrimSpace(ident[:start]), ident[start+1 : end], nil
+}
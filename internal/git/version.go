@@ -0,0 +1,80 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Version is a git release number
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// MinRemoteRuleVersion is the first git release that understands the
+// hasconfig:remote.*.url includeIf condition; older releases ignore it
+var MinRemoteRuleVersion = Version{2, 36, 0}
+
+// String returns the version as "major.minor.patch"
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is the same release as other or a later one
+func (v Version) AtLeast(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+// ParseVersion parses the output of `git --version`, such as
+// "git version 2.39.2" or "git version 2.39.3 (Apple Git-145)"
+func ParseVersion(output string) (Version, error) {
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return Version{}, fmt.Errorf("unrecognized git version output: %q", strings.TrimSpace(output))
+	}
+
+	// Vendor builds append components, e.g. 2.45.1.windows.1
+	parts := strings.Split(fields[2], ".")
+	var numbers [3]int
+	for i := 0; i < len(numbers) && i < len(parts); i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			if i < 2 {
+				return Version{}, fmt.Errorf("unrecognized git version: %s", fields[2])
+			}
+			break
+		}
+		numbers[i] = n
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// InstalledVersion returns the version of the git on PATH
+func InstalledVersion() (Version, error) {
+	out, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		return Version{}, fmt.Errorf("git is not installed or not in PATH")
+	}
+	return ParseVersion(string(out))
+}
+
+// CheckRemoteRuleSupport returns an error if the installed git is too old
+// for remote rules
+func CheckRemoteRuleSupport() error {
+	version, err := InstalledVersion()
+	if err != nil {
+		return err
+	}
+	if !version.AtLeast(MinRemoteRuleVersion) {
+		return fmt.Errorf("remote rules need git %d.%d or newer, but git %s is installed", MinRemoteRuleVersion.Major, MinRemoteRuleVersion.Minor, version)
+	}
+	return nil
+}
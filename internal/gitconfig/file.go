@@ -0,0 +1,534 @@
+package gitconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// File is a parsed gitconfig file.
+//
+// The file keeps every line it was parsed from, so comments, blank lines and
+// formatting survive a round trip; only entries that are added or changed are
+// re-rendered.
+type File struct {
+	// preamble holds comments and blank lines before the first section
+	preamble []*line
+	sections []*Section
+	// newline terminates new lines: "\r\n" for files whose first line ends
+	// with CRLF, otherwise "\n"
+	newline string
+}
+
+// Section is one [section] or [section "subsection"] block. A name may
+// appear in several blocks; lookups consider all of them in file order.
+type Section struct {
+	// Name is the section name as written; comparisons are case-insensitive
+	Name string
+	// Subsection is the case-sensitive subsection, if any
+	Subsection string
+
+	hasSubsection bool
+	header        string
+	headerEOL     string
+	lines         []*line
+}
+
+// line is an entry, comment or blank line, with its original text
+type line struct {
+	raw string
+	// eol is the terminator the line was parsed with; empty for new lines
+	eol string
+
+	// key is the variable name as written; empty for comments and blanks
+	key      string
+	value    string
+	implicit bool
+}
+
+// Entry is a single variable in a section
+type Entry struct {
+	Key   string
+	Value string
+	// Implicit is set for a bare "key" line, which git treats as true
+	Implicit bool
+}
+
+// New returns an empty gitconfig file
+func New() *File {
+	return &File{}
+}
+
+// Load reads and parses a gitconfig file. A missing file yields an empty one.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Sections returns the file's sections in order
+func (f *File) Sections() []*Section {
+	return f.sections
+}
+
+// Entries returns the section's variables in order
+func (s *Section) Entries() []Entry {
+	var entries []Entry
+	for _, l := range s.lines {
+		if l.key != "" {
+			entries = append(entries, Entry{Key: l.key, Value: l.value, Implicit: l.implicit})
+		}
+	}
+	return entries
+}
+
+// Is reports whether the section has the given name and subsection. An
+// empty subsection means none: [name ""] blocks are not matched.
+func (s *Section) Is(name, subsection string) bool {
+	return s.matches(sectionKey{name: name, subsection: subsection, hasSubsection: subsection != ""})
+}
+
+// sectionKey identifies the blocks a key lives in. hasSubsection tells
+// "section..name", whose subsection is empty, apart from "section.name".
+type sectionKey struct {
+	name          string
+	subsection    string
+	hasSubsection bool
+}
+
+// matches reports whether the section is one of the blocks k identifies
+func (s *Section) matches(k sectionKey) bool {
+	return strings.EqualFold(s.Name, k.name) && s.Subsection == k.subsection && s.hasSubsection == k.hasSubsection
+}
+
+// SplitKey breaks "section.subsection.name" into its parts. The subsection
+// may itself contain dots; the section and name may not.
+func SplitKey(key string) (section, subsection, name string, err error) {
+	k, name, err := parseKey(key)
+	return k.name, k.subsection, name, err
+}
+
+// parseKey breaks a key into the blocks it lives in and its variable name
+func parseKey(key string) (sectionKey, string, error) {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first <= 0 || last == len(key)-1 {
+		return sectionKey{}, "", fmt.Errorf("invalid config key: %s", key)
+	}
+
+	k := sectionKey{name: key[:first], hasSubsection: first != last}
+	if k.hasSubsection {
+		k.subsection = key[first+1 : last]
+	}
+	return k, key[last+1:], nil
+}
+
+// CanonicalKey validates a key and returns it in git's canonical form:
+// section and variable names lower-cased, the subsection left as is
+func CanonicalKey(key string) (string, error) {
+	k, name, err := parseKey(key)
+	if err != nil {
+		return "", err
+	}
+	if !validName(name) {
+		return "", fmt.Errorf("invalid config key name: %s", name)
+	}
+	for i := 0; i < len(k.name); i++ {
+		if !isAlnum(k.name[i]) && k.name[i] != '-' {
+			return "", fmt.Errorf("invalid config section: %s", k.name)
+		}
+	}
+	if strings.ContainsAny(k.subsection, "\n\x00") {
+		return "", fmt.Errorf("invalid config subsection: %q", k.subsection)
+	}
+
+	if !k.hasSubsection {
+		return strings.ToLower(k.name) + "." + strings.ToLower(name), nil
+	}
+	return strings.ToLower(k.name) + "." + k.subsection + "." + strings.ToLower(name), nil
+}
+
+// GetAll returns every value of a key in file order
+func (f *File) GetAll(key string) []string {
+	k, name, err := parseKey(key)
+	if err != nil {
+		return nil
+	}
+
+	var values []string
+	for _, s := range f.sections {
+		if !s.matches(k) {
+			continue
+		}
+		for _, l := range s.lines {
+			if l.key != "" && strings.EqualFold(l.key, name) {
+				values = append(values, l.value)
+			}
+		}
+	}
+	return values
+}
+
+// Get returns the last value of a key, as git does for single-valued keys
+func (f *File) Get(key string) (string, bool) {
+	values := f.GetAll(key)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// GetBool returns a key interpreted as a git boolean. Bare keys are true.
+func (f *File) GetBool(key string) (bool, error) {
+	k, name, err := parseKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	var last *line
+	for _, s := range f.sections {
+		if !s.matches(k) {
+			continue
+		}
+		for _, l := range s.lines {
+			if l.key != "" && strings.EqualFold(l.key, name) {
+				last = l
+			}
+		}
+	}
+
+	if last == nil {
+		return false, nil
+	}
+	if last.implicit {
+		return true, nil
+	}
+	return ParseBool(last.value)
+}
+
+// ParseBool interprets a value the way git does for boolean variables
+func ParseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean value: %s", value)
+}
+
+// Set assigns a single value to a key. The last existing occurrence is
+// updated in place and any other occurrences are removed; otherwise the key is
+// appended to the last matching section, which is created if necessary.
+func (f *File) Set(key, value string) error {
+	k, name, err := parseKey(key)
+	if err != nil {
+		return err
+	}
+
+	var target *line
+	for _, s := range f.sections {
+		if !s.matches(k) {
+			continue
+		}
+		for _, l := range s.lines {
+			if l.key != "" && strings.EqualFold(l.key, name) {
+				target = l
+			}
+		}
+	}
+
+	if target == nil {
+		return f.Add(key, value)
+	}
+
+	if target.value != value || target.implicit {
+		target.value = value
+		target.implicit = false
+		target.raw = ""
+	}
+
+	// Drop every other occurrence so the key is single-valued
+	for _, s := range f.sections {
+		if !s.matches(k) {
+			continue
+		}
+		kept := s.lines[:0]
+		for _, l := range s.lines {
+			if l == target || l.key == "" || !strings.EqualFold(l.key, name) {
+				kept = append(kept, l)
+			}
+		}
+		s.lines = kept
+	}
+
+	return nil
+}
+
+// Add appends a value to a key, keeping existing values (multi-valued keys)
+func (f *File) Add(key, value string) error {
+	k, name, err := parseKey(key)
+	if err != nil {
+		return err
+	}
+	if !validName(name) {
+		return fmt.Errorf("invalid config key name: %s", name)
+	}
+
+	s := f.lastSection(k)
+	if s == nil {
+		s = f.addSection(k)
+	}
+	s.add(name, value)
+	return nil
+}
+
+// add appends an entry after the section's last one, so trailing comments
+// and blank lines stay at the end of the section
+func (s *Section) add(name, value string) {
+	insertAt := len(s.lines)
+	for i := len(s.lines) - 1; i >= 0; i-- {
+		if s.lines[i].key != "" {
+			insertAt = i + 1
+			break
+		}
+	}
+
+	entry := &line{key: name, value: value}
+	s.lines = append(s.lines[:insertAt], append([]*line{entry}, s.lines[insertAt:]...)...)
+}
+
+// Set assigns a single value to a key within this block only. Other blocks
+// with the same name and subsection are left alone.
+func (s *Section) Set(name, value string) error {
+	if !validName(name) {
+		return fmt.Errorf("invalid config key name: %s", name)
+	}
+
+	var target *line
+	for _, l := range s.lines {
+		if l.key != "" && strings.EqualFold(l.key, name) {
+			target = l
+		}
+	}
+	if target == nil {
+		s.add(name, value)
+		return nil
+	}
+
+	if target.value != value || target.implicit {
+		target.value = value
+		target.implicit = false
+		target.raw = ""
+	}
+
+	kept := s.lines[:0]
+	for _, l := range s.lines {
+		if l == target || l.key == "" || !strings.EqualFold(l.key, name) {
+			kept = append(kept, l)
+		}
+	}
+	s.lines = kept
+	return nil
+}
+
+// Unset removes every value of a key, reporting whether anything was removed.
+// Sections left without entries or comments are removed as well.
+func (f *File) Unset(key string) (bool, error) {
+	k, name, err := parseKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	removed := false
+	for _, s := range f.sections {
+		if !s.matches(k) {
+			continue
+		}
+		kept := s.lines[:0]
+		for _, l := range s.lines {
+			if l.key != "" && strings.EqualFold(l.key, name) {
+				removed = true
+				continue
+			}
+			kept = append(kept, l)
+		}
+		s.lines = kept
+	}
+
+	if removed {
+		f.dropEmptySections(k)
+	}
+	return removed, nil
+}
+
+// RemoveSection deletes every block with the given name and subsection,
+// reporting whether any existed
+func (f *File) RemoveSection(name, subsection string) bool {
+	return f.RemoveSections(func(s *Section) bool {
+		return s.Is(name, subsection)
+	}) > 0
+}
+
+// RemoveSections deletes the blocks selected by match, returning how many
+// were removed
+func (f *File) RemoveSections(match func(*Section) bool) int {
+	kept := f.sections[:0]
+	removed := 0
+	for _, s := range f.sections {
+		if match(s) {
+			removed++
+			continue
+		}
+		kept = append(kept, s)
+	}
+	f.sections = kept
+	return removed
+}
+
+// SortSections stably sorts the sections selected by include among the
+// positions they occupy; other sections stay where they are
+func (f *File) SortSections(include func(*Section) bool, less func(a, b *Section) bool) {
+	var positions []int
+	var selected []*Section
+	for i, s := range f.sections {
+		if include(s) {
+			positions = append(positions, i)
+			selected = append(selected, s)
+		}
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return less(selected[i], selected[j])
+	})
+
+	for i, position := range positions {
+		f.sections[position] = selected[i]
+	}
+}
+
+// AddSection appends a new, empty section block
+func (f *File) AddSection(name, subsection string) *Section {
+	return f.addSection(sectionKey{name: name, subsection: subsection, hasSubsection: subsection != ""})
+}
+
+func (f *File) addSection(k sectionKey) *Section {
+	s := &Section{
+		Name:          k.name,
+		Subsection:    k.subsection,
+		hasSubsection: k.hasSubsection,
+	}
+	f.sections = append(f.sections, s)
+	return s
+}
+
+// InsertSection inserts a new, empty section block at the given position
+// among the file's sections
+func (f *File) InsertSection(index int, name, subsection string) *Section {
+	if index < 0 || index > len(f.sections) {
+		index = len(f.sections)
+	}
+	s := &Section{
+		Name:          name,
+		Subsection:    subsection,
+		hasSubsection: subsection != "",
+	}
+	f.sections = append(f.sections[:index], append([]*Section{s}, f.sections[index:]...)...)
+	return s
+}
+
+// AddComment appends a comment line to the preamble, or to the last section
+// if the file already has sections. The text should not include the marker.
+func (f *File) AddComment(text string) {
+	l := &line{raw: "# " + text}
+	if len(f.sections) == 0 {
+		f.preamble = append(f.preamble, l)
+		return
+	}
+	last := f.sections[len(f.sections)-1]
+	last.lines = append(last.lines, l)
+}
+
+// lastSection returns the last of the blocks k identifies
+func (f *File) lastSection(k sectionKey) *Section {
+	for i := len(f.sections) - 1; i >= 0; i-- {
+		if f.sections[i].matches(k) {
+			return f.sections[i]
+		}
+	}
+	return nil
+}
+
+// dropEmptySections removes matching blocks that contain only blank lines
+func (f *File) dropEmptySections(k sectionKey) {
+	kept := f.sections[:0]
+	for _, s := range f.sections {
+		if s.matches(k) && s.empty() {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	f.sections = kept
+}
+
+// empty reports whether a section has no entries or comments
+func (s *Section) empty() bool {
+	for _, l := range s.lines {
+		if strings.TrimSpace(l.raw) != "" || l.key != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Include is an include or includeIf directive
+type Include struct {
+	// Condition is the includeIf condition, empty for a plain include
+	Condition string
+	// Path is the included file as written
+	Path string
+}
+
+// Includes returns every include.path and includeIf.<condition>.path entry
+func (f *File) Includes() []Include {
+	var includes []Include
+	for _, s := range f.sections {
+		isInclude := strings.EqualFold(s.Name, "include") && !s.hasSubsection
+		isIncludeIf := strings.EqualFold(s.Name, "includeIf") && s.hasSubsection
+		if !isInclude && !isIncludeIf {
+			continue
+		}
+		for _, entry := range s.Entries() {
+			if strings.EqualFold(entry.Key, "path") {
+				includes = append(includes, Include{Condition: s.Subsection, Path: entry.Value})
+			}
+		}
+	}
+	return includes
+}
+
+// ResolvePath expands an include path the way git does: a leading ~/ refers
+// to the home directory and relative paths are relative to the including file
+func ResolvePath(path, includingFile string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(filepath.Dir(includingFile), path)
+	}
+	return path
+}
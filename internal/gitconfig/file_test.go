@@ -0,0 +1,223 @@
+package gitconfig
+
+import "testing"
+
+// sample has blocks with no subsection, an empty subsection, a subsection
+// with dots and a legacy [section.subsection] header
+const sample = "[x]\n\tk = a\n[x \"\"]\n\tk = b\n[x \"Sub.Dots\"]\n\tk = c\n[y.Sub]\n\tk = d\n" +
+	"[a]\n\tv = \"  spaced \\\"q\\\" \" ; trailing\n\tw = one\\\n two\n\tbare\n"
+
+func TestParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"preamble", "# generated\n\n[user]\n\tname = A\n\temail = a@example.com\n"},
+		{"crlf", "[user]\r\n\tname = A\r\n\r\n; comment\r\n[core]\r\n\teditor = vim\r\n"},
+		{"mixed line endings", "[a]\r\n\tk = v\n\n[b]\n\tk = v\r\n"},
+		{"odd spacing", "[a]\nk=v\n   k2   =   v2   \n\t[b \"s\"]\n"},
+		{"quoting and continuations", sample},
+	}
+
+	for _, tt := range tests {
+		f, err := Parse([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: Parse() error = %v", tt.name, err)
+			continue
+		}
+		if got := string(f.Bytes()); got != tt.data {
+			t.Errorf("%s: Bytes() = %q, want %q", tt.name, got, tt.data)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		key   string
+		want  string
+		found bool
+	}{
+		{"x.k", "a", true},
+		{"X.K", "a", true},
+		{"x..k", "b", true},
+		{"x.Sub.Dots.k", "c", true},
+		{"x.sub.dots.k", "", false},
+		{"y.sub.k", "d", true},
+		{"y.Sub.k", "", false},
+		{"a.v", "  spaced \"q\" ", true},
+		{"a.w", "one two", true},
+		{"a.bare", "", true},
+		{"a.missing", "", false},
+	}
+
+	f, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for _, tt := range tests {
+		got, found := f.Get(tt.key)
+		if got != tt.want || found != tt.found {
+			t.Errorf("Get(%q) = %q, %v, want %q, %v", tt.key, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		key, value string
+		want       string
+	}{
+		{
+			name: "empty subsection",
+			data: "[x]\n\tk = a\n[x \"\"]\n\tk = b\n",
+			key:  "x..k", value: "new",
+			want: "[x]\n\tk = a\n[x \"\"]\n\tk = new\n",
+		},
+		{
+			name: "no subsection",
+			data: "[x]\n\tk = a\n[x \"\"]\n\tk = b\n",
+			key:  "x.k", value: "new",
+			want: "[x]\n\tk = new\n[x \"\"]\n\tk = b\n",
+		},
+		{
+			name: "new empty subsection",
+			data: "[x]\n\tk = a\n",
+			key:  "x..k", value: "b",
+			want: "[x]\n\tk = a\n[x \"\"]\n\tk = b\n",
+		},
+		{
+			name: "multiple values",
+			data: "[x]\n\tk = a\n\tk = b\n[x]\n\tk = c\n",
+			key:  "x.k", value: "d",
+			want: "[x]\n[x]\n\tk = d\n",
+		},
+		{
+			name: "after trailing comment",
+			data: "[x]\n\tk = a\n\t# end of x\n",
+			key:  "x.j", value: "b",
+			want: "[x]\n\tk = a\n\tj = b\n\t# end of x\n",
+		},
+		{
+			name: "quoted value",
+			data: "[x]\n",
+			key:  "x.k", value: " lead; \"q\"",
+			want: "[x]\n\tk = \" lead; \\\"q\\\"\"\n",
+		},
+		{
+			name: "no trailing newline",
+			data: "[x]\n\tk = a",
+			key:  "x.j", value: "b",
+			want: "[x]\n\tk = a\n\tj = b\n",
+		},
+		{
+			name: "crlf",
+			data: "[x]\r\n\tk = a\r\n",
+			key:  "x.j", value: "b",
+			want: "[x]\r\n\tk = a\r\n\tj = b\r\n",
+		},
+		{
+			name: "crlf unchanged lines",
+			data: "[x]\r\n\tk = a\n",
+			key:  "y.k", value: "b",
+			want: "[x]\r\n\tk = a\n[y]\r\n\tk = b\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		f, err := Parse([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: Parse() error = %v", tt.name, err)
+			continue
+		}
+		if err := f.Set(tt.key, tt.value); err != nil {
+			t.Errorf("%s: Set() error = %v", tt.name, err)
+			continue
+		}
+		if got := string(f.Bytes()); got != tt.want {
+			t.Errorf("%s: Bytes() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnset(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		removed bool
+	}{
+		{"x.k", "[x \"\"]\n\tk = b\n", true},
+		{"x..k", "[x]\n\tk = a\n", true},
+		{"x.j", "[x]\n\tk = a\n[x \"\"]\n\tk = b\n", false},
+	}
+
+	for _, tt := range tests {
+		f, err := Parse([]byte("[x]\n\tk = a\n[x \"\"]\n\tk = b\n"))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		removed, err := f.Unset(tt.key)
+		if err != nil {
+			t.Errorf("Unset(%q) error = %v", tt.key, err)
+			continue
+		}
+		if got := string(f.Bytes()); got != tt.want || removed != tt.removed {
+			t.Errorf("Unset(%q) = %v, %q, want %v, %q", tt.key, removed, got, tt.removed, tt.want)
+		}
+	}
+}
+
+func TestCanonicalKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"User.Email", "user.email", false},
+		{"includeIf.gitdir:~/Work/.path", "includeif.gitdir:~/Work/.path", false},
+		{"X..K", "x..k", false},
+		{"url.git@github.com:.insteadOf", "url.git@github.com:.insteadof", false},
+		{"user", "", true},
+		{".email", "", true},
+		{"user.", "", true},
+		{"user.1email", "", true},
+		{"us_er.email", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := CanonicalKey(tt.key)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("CanonicalKey(%q) = %q, %v, want %q, error %v", tt.key, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	values := []string{
+		"",
+		"plain",
+		"inner  space",
+		" leading",
+		"trailing\t",
+		"hash#value",
+		"semi;colon",
+		`quote"d`,
+		`back\slash`,
+		"line\nbreak",
+		"tab\there",
+		"C:\\Users\\me",
+	}
+
+	for _, value := range values {
+		f, err := Parse([]byte("[a]\n\tv = " + FormatValue(value) + "\n"))
+		if err != nil {
+			t.Errorf("FormatValue(%q) = %q does not parse: %v", value, FormatValue(value), err)
+			continue
+		}
+		if got, _ := f.Get("a.v"); got != value {
+			t.Errorf("FormatValue(%q) = %q, parsed back as %q", value, FormatValue(value), got)
+		}
+	}
+}
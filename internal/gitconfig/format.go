@@ -0,0 +1,92 @@
+package gitconfig
+
+import (
+	"strings"
+)
+
+// Bytes renders the file. Unmodified lines are written exactly as they were
+// parsed, line endings included; new or changed entries use git's canonical
+// "\tkey = value" form and end the way the file's first line does.
+func (f *File) Bytes() []byte {
+	var b strings.Builder
+	newline := f.newline
+	if newline == "" {
+		newline = "\n"
+	}
+	terminate := func(eol string) {
+		if eol == "" {
+			eol = newline
+		}
+		b.WriteString(eol)
+	}
+
+	for _, l := range f.preamble {
+		b.WriteString(l.render())
+		terminate(l.eol)
+	}
+
+	for _, s := range f.sections {
+		if s.header != "" {
+			b.WriteString(s.header)
+		} else {
+			b.WriteString(formatHeader(s))
+		}
+		terminate(s.headerEOL)
+
+		for _, l := range s.lines {
+			b.WriteString(l.render())
+			terminate(l.eol)
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// render returns the text of a line, formatting entries that have no original text
+func (l *line) render() string {
+	if l.raw != "" || l.key == "" {
+		return l.raw
+	}
+	if l.implicit {
+		return "\t" + l.key
+	}
+	return "\t" + l.key + " = " + FormatValue(l.value)
+}
+
+// formatHeader renders a section header
+func formatHeader(s *Section) string {
+	if !s.hasSubsection {
+		return "[" + s.Name + "]"
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s.Subsection)
+	return "[" + s.Name + ` "` + escaped + `"]`
+}
+
+// FormatValue encodes a value so that git parses it back unchanged, quoting
+// it when it has surrounding whitespace or comment characters
+func FormatValue(value string) string {
+	var b strings.Builder
+	needsQuotes := value != strings.TrimSpace(value) || strings.ContainsAny(value, "#;")
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	if needsQuotes {
+		return `"` + b.String() + `"`
+	}
+	return b.String()
+}
@@ -0,0 +1,328 @@
+package gitconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parser walks a gitconfig document one character at a time
+type parser struct {
+	data string
+	pos  int
+	line int
+}
+
+// Parse parses a gitconfig document in git's INI dialect: [section] and
+// [section "subsection"] headers (plus the legacy [section.subsection] form),
+// quoted values with \", \\, \n, \t and \b escapes, backslash line
+// continuations, bare boolean keys, and # or ; comments.
+func Parse(data []byte) (*File, error) {
+	p := &parser{data: string(data), line: 1}
+	f := &File{}
+	if end := strings.IndexByte(p.data, '\n'); end > 0 && p.data[end-1] == '\r' {
+		f.newline = "\r\n"
+	}
+	var current *Section
+
+	for p.pos < len(p.data) {
+		start := p.pos
+		p.skipSpace()
+
+		if p.atEOL() {
+			raw := p.data[start:p.lineEnd(start)]
+			f.appendLine(current, &line{raw: raw, eol: p.consumeEOL()})
+			continue
+		}
+
+		switch c := p.data[p.pos]; {
+		case c == '#' || c == ';':
+			p.skipToEOL()
+			raw := p.data[start:p.pos]
+			f.appendLine(current, &line{raw: raw, eol: p.consumeEOL()})
+
+		case c == '[':
+			section, err := p.parseHeader()
+			if err != nil {
+				return nil, err
+			}
+			section.header = p.data[start:p.pos]
+			f.sections = append(f.sections, section)
+			current = section
+
+			// Anything after the header on the same line is an entry or comment
+			p.skipSpace()
+			if p.atEOL() {
+				section.headerEOL = p.consumeEOL()
+			}
+
+		case isLetter(c):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: variable outside of any section", p.line)
+			}
+			entry, err := p.parseEntry()
+			if err != nil {
+				return nil, err
+			}
+			entry.raw = p.data[start:p.pos]
+			entry.eol = p.consumeEOL()
+			current.lines = append(current.lines, entry)
+
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", p.line, c)
+		}
+	}
+
+	return f, nil
+}
+
+// appendLine adds a comment or blank line to the current section or preamble
+func (f *File) appendLine(current *Section, l *line) {
+	if current == nil {
+		f.preamble = append(f.preamble, l)
+		return
+	}
+	current.lines = append(current.lines, l)
+}
+
+// parseHeader parses "[name]", "[name "subsection"]" or "[name.subsection]"
+func (p *parser) parseHeader() (*Section, error) {
+	p.pos++ // [
+
+	start := p.pos
+	for p.pos < len(p.data) && (isAlnum(p.data[p.pos]) || p.data[p.pos] == '-' || p.data[p.pos] == '.') {
+		p.pos++
+	}
+	name := p.data[start:p.pos]
+	if name == "" {
+		return nil, fmt.Errorf("line %d: empty section name", p.line)
+	}
+
+	section := &Section{Name: name}
+
+	// Legacy [section.subsection] syntax; the subsection is case-insensitive
+	if dot := strings.Index(name, "."); dot >= 0 {
+		section.Name = name[:dot]
+		section.Subsection = strings.ToLower(name[dot+1:])
+		section.hasSubsection = true
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == '"' {
+		if section.hasSubsection {
+			return nil, fmt.Errorf("line %d: invalid section header", p.line)
+		}
+		subsection, err := p.parseSubsection()
+		if err != nil {
+			return nil, err
+		}
+		section.Subsection = subsection
+		section.hasSubsection = true
+	}
+
+	if p.pos >= len(p.data) || p.data[p.pos] != ']' {
+		return nil, fmt.Errorf("line %d: unterminated section header", p.line)
+	}
+	p.pos++
+
+	return section, nil
+}
+
+// parseSubsection parses a quoted subsection name
+func (p *parser) parseSubsection() (string, error) {
+	p.pos++ // opening quote
+
+	var b strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch c {
+		case '\n':
+			return "", fmt.Errorf("line %d: newline in subsection name", p.line)
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			p.pos++
+			if p.pos >= len(p.data) || p.data[p.pos] == '\n' {
+				return "", fmt.Errorf("line %d: unterminated subsection name", p.line)
+			}
+			b.WriteByte(p.data[p.pos])
+		default:
+			b.WriteByte(c)
+		}
+		p.pos++
+	}
+
+	return "", fmt.Errorf("line %d: unterminated subsection name", p.line)
+}
+
+// parseEntry parses "name = value" or a bare "name"
+func (p *parser) parseEntry() (*line, error) {
+	start := p.pos
+	for p.pos < len(p.data) && (isAlnum(p.data[p.pos]) || p.data[p.pos] == '-') {
+		p.pos++
+	}
+	entry := &line{key: p.data[start:p.pos]}
+
+	p.skipSpace()
+	if p.atEOL() || p.data[p.pos] == '#' || p.data[p.pos] == ';' {
+		p.skipToEOL()
+		entry.implicit = true
+		return entry, nil
+	}
+
+	if p.data[p.pos] != '=' {
+		return nil, fmt.Errorf("line %d: expected '=' after %s", p.line, entry.key)
+	}
+	p.pos++
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	entry.value = value
+	return entry, nil
+}
+
+// parseValue parses a value up to the end of its (possibly continued) line.
+// Unquoted leading and trailing whitespace is dropped; internal whitespace is
+// kept verbatim.
+func (p *parser) parseValue() (string, error) {
+	var b strings.Builder
+	inQuote := false
+	started := false
+	pendingSpace := ""
+
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+
+		if c == '\n' {
+			break
+		}
+		if c == '\r' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '\n' {
+			break
+		}
+
+		if !inQuote && (c == ' ' || c == '\t') {
+			if started {
+				pendingSpace += string(c)
+			}
+			p.pos++
+			continue
+		}
+		if !inQuote && (c == '#' || c == ';') {
+			p.skipToEOL()
+			break
+		}
+
+		b.WriteString(pendingSpace)
+		pendingSpace = ""
+		started = true
+
+		switch c {
+		case '\\':
+			p.pos++
+			if p.pos >= len(p.data) {
+				return "", fmt.Errorf("line %d: trailing backslash", p.line)
+			}
+			switch e := p.data[p.pos]; e {
+			case '\n':
+				p.line++ // Line continuation
+			case '\r':
+				if p.pos+1 < len(p.data) && p.data[p.pos+1] == '\n' {
+					p.pos++
+					p.line++
+				}
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case '"', '\\':
+				b.WriteByte(e)
+			default:
+				return "", fmt.Errorf("line %d: invalid escape sequence \\%c", p.line, e)
+			}
+		case '"':
+			inQuote = !inQuote
+		default:
+			b.WriteByte(c)
+		}
+		p.pos++
+	}
+
+	if inQuote {
+		return "", fmt.Errorf("line %d: unterminated quoted value", p.line)
+	}
+	return b.String(), nil
+}
+
+// skipSpace skips spaces and tabs
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// atEOL reports whether the parser is at the end of a line or the input
+func (p *parser) atEOL() bool {
+	if p.pos >= len(p.data) {
+		return true
+	}
+	c := p.data[p.pos]
+	return c == '\n' || (c == '\r' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '\n')
+}
+
+// skipToEOL advances to the end of the current line
+func (p *parser) skipToEOL() {
+	for !p.atEOL() {
+		p.pos++
+	}
+}
+
+// consumeEOL moves past the line terminator, if any, and returns it
+func (p *parser) consumeEOL() string {
+	start := p.pos
+	if p.pos < len(p.data) && p.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+		p.pos++
+		p.line++
+	}
+	return p.data[start:p.pos]
+}
+
+// lineEnd returns the end of the line that starts at start, excluding the terminator
+func (p *parser) lineEnd(start int) int {
+	end := strings.IndexByte(p.data[start:], '\n')
+	if end < 0 {
+		return len(p.data)
+	}
+	end += start
+	if end > start && p.data[end-1] == '\r' {
+		end--
+	}
+	return end
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isAlnum(c byte) bool {
+	return isLetter(c) || (c >= '0' && c <= '9')
+}
+
+// validName reports whether name is a valid variable name
+func validName(name string) bool {
+	if name == "" || !isLetter(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isAlnum(name[i]) && name[i] != '-' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package gpg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// expiryWarning is how far ahead an upcoming expiration is reported
+const expiryWarning = 30 * 24 * time.Hour
+
+// Issue is a problem found with a profile's signing key
+type Issue struct {
+	Message string
+	// Blocking issues make signing fail outright; the others are warnings
+	Blocking bool
+}
+
+// CheckSigningKey verifies that keyID names a secret key in keys that can
+// sign at now, and that one of its user IDs matches one of emails
+func CheckSigningKey(keys []*Key, keyID string, emails []string, now time.Time) []Issue {
+	key, named := Find(keys, keyID)
+	if key == nil {
+		return []Issue{{Message: fmt.Sprintf("GPG secret key %s is not in the keyring", keyID), Blocking: true}}
+	}
+
+	var issues []Issue
+	blocking := func(format string, args ...any) {
+		issues = append(issues, Issue{Message: fmt.Sprintf(format, args...), Blocking: true})
+	}
+
+	switch {
+	case key.Revoked():
+		blocking("GPG key %s has been revoked", keyID)
+	case key.Expired(now):
+		blocking("GPG key %s expired on %s", keyID, key.Expires.Format("2006-01-02"))
+	case named != &key.Subkey:
+		// A specific subkey was named: gpg signs with exactly that subkey
+		switch {
+		case named.Revoked():
+			blocking("GPG subkey %s has been revoked", keyID)
+		case named.Expired(now):
+			blocking("GPG subkey %s expired on %s", keyID, named.Expires.Format("2006-01-02"))
+		case !named.CanSign():
+			blocking("GPG subkey %s cannot sign (capabilities: %s)", keyID, named.Capabilities)
+		case !named.SecretAvailable:
+			blocking("the secret part of GPG subkey %s is not available", keyID)
+		}
+	default:
+		if key.signingKey(now) == nil {
+			if !key.SecretAvailable && !hasSecretSubkey(key) {
+				blocking("the secret part of GPG key %s is not available", keyID)
+			} else {
+				blocking("GPG key %s has no usable signing key or subkey", keyID)
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		return issues
+	}
+
+	signing := named
+	if named == &key.Subkey {
+		signing = key.signingKey(now)
+	}
+	expires := key.Expires
+	if !signing.Expires.IsZero() && (expires.IsZero() || signing.Expires.Before(expires)) {
+		expires = signing.Expires
+	}
+	if !expires.IsZero() && expires.Sub(now) < expiryWarning {
+		issues = append(issues, Issue{Message: fmt.Sprintf("GPG key %s expires on %s", keyID, expires.Format("2006-01-02"))})
+	}
+
+	matched := false
+	for _, email := range emails {
+		if key.HasEmail(email) {
+			matched = true
+			break
+		}
+	}
+	if !matched && len(emails) > 0 {
+		keyEmails := key.Emails()
+		if len(keyEmails) == 0 {
+			keyEmails = []string{"none"}
+		}
+		issues = append(issues, Issue{Message: fmt.Sprintf("GPG key %s has no user ID for %s (key emails: %s)", keyID, strings.Join(emails, ", "), strings.Join(keyEmails, ", "))})
+	}
+
+	return issues
+}
+
+// hasSecretSubkey reports whether any subkey's secret part is available
+func hasSecretSubkey(key *Key) bool {
+	for _, sub := range key.Subkeys {
+		if sub.SecretAvailable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package gpg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckSigningKey(t *testing.T) {
+	// listed is when the captured listings were taken
+	listed := unix(1792196062)
+	work := []string{"work@example.com"}
+
+	tests := []struct {
+		name   string
+		data   string
+		keyID  string
+		emails []string
+		now    time.Time
+		want   []Issue
+	}{
+		{
+			name:  "usable key",
+			data:  workListing,
+			keyID: "97BBE5943394F4AE", emails: work, now: unix(1793924054),
+		},
+		{
+			name:  "signing subkey expires before primary",
+			data:  workListing,
+			keyID: "3355BCBCA547D28E9D70B9EA97BBE5943394F4AE", emails: work, now: listed,
+			want: []Issue{{Message: "GPG key 3355BCBCA547D28E9D70B9EA97BBE5943394F4AE expires on 2026-11-06"}},
+		},
+		{
+			name:  "named encryption subkey",
+			data:  workListing,
+			keyID: "D64FD3231D336DA6", emails: work, now: listed,
+			want: []Issue{{Message: "GPG subkey D64FD3231D336DA6 cannot sign (capabilities: e)", Blocking: true}},
+		},
+		{
+			name:  "primary expires before subkey",
+			data:  strings.Replace(workListing, "1823732051", "1855268060", 1),
+			keyID: "B334B7A3196A3020", emails: work, now: unix(1855268049 - 24*60*60),
+			want: []Issue{{Message: "GPG key B334B7A3196A3020 expires on 2028-10-16"}},
+		},
+		{
+			name:  "expired primary",
+			data:  workListing,
+			keyID: "97BBE5943394F4AE", emails: work, now: unix(1855268049),
+			want: []Issue{{Message: "GPG key 97BBE5943394F4AE expired on 2028-10-16", Blocking: true}},
+		},
+		{
+			name:  "expired named subkey",
+			data:  workListing,
+			keyID: "976F57DD3D911D7F", emails: work, now: unix(1793924054),
+			want: []Issue{{Message: "GPG subkey 976F57DD3D911D7F expired on 2026-11-06", Blocking: true}},
+		},
+		{
+			name:  "all signing subkeys expired",
+			data:  workListing,
+			keyID: "97BBE5943394F4AE", emails: work, now: unix(1823732051),
+			want: []Issue{{Message: "GPG key 97BBE5943394F4AE has no usable signing key or subkey", Blocking: true}},
+		},
+		{
+			name:  "revoked primary",
+			data:  strings.Replace(workListing, "sec:u:", "sec:r:", 1),
+			keyID: "97BBE5943394F4AE", emails: work, now: listed,
+			want: []Issue{{Message: "GPG key 97BBE5943394F4AE has been revoked", Blocking: true}},
+		},
+		{
+			name:  "revoked named subkey",
+			data:  strings.Replace(workListing, "ssb:u:255:22:B334", "ssb:r:255:22:B334", 1),
+			keyID: "B334B7A3196A3020", emails: work, now: listed,
+			want: []Issue{{Message: "GPG subkey B334B7A3196A3020 has been revoked", Blocking: true}},
+		},
+		{
+			name:  "stub named subkey",
+			data:  strings.Replace(workListing, ":s:::+::ed25519::\nfpr:::::::::0DB1", ":s:::#::ed25519::\nfpr:::::::::0DB1", 1),
+			keyID: "B334B7A3196A3020", emails: work, now: listed,
+			want: []Issue{{Message: "the secret part of GPG subkey B334B7A3196A3020 is not available", Blocking: true}},
+		},
+		{
+			name:  "stub primary with secret subkeys",
+			data:  homeListing,
+			keyID: "40488B06DB0D40DB", emails: []string{"home@example.com"}, now: listed,
+		},
+		{
+			name:  "stub primary and subkeys",
+			data:  strings.ReplaceAll(homeListing, ":s:::+::", ":s:::#::"),
+			keyID: "40488B06DB0D40DB", emails: []string{"home@example.com"}, now: listed,
+			want: []Issue{{Message: "the secret part of GPG key 40488B06DB0D40DB is not available", Blocking: true}},
+		},
+		{
+			name:  "not in keyring",
+			data:  workListing,
+			keyID: "0123456789ABCDEF", emails: work, now: listed,
+			want: []Issue{{Message: "GPG secret key 0123456789ABCDEF is not in the keyring", Blocking: true}},
+		},
+		{
+			name:  "any profile email matches",
+			data:  homeListing,
+			keyID: "40488B06DB0D40DB", emails: []string{"other@example.com", "HOME@example.com"}, now: listed,
+		},
+		{
+			name:  "only a revoked uid matches",
+			data:  workListing,
+			keyID: "B334B7A3196A3020", emails: []string{"old@example.com"}, now: listed,
+			want: []Issue{{Message: "GPG key B334B7A3196A3020 has no user ID for old@example.com (key emails: work@example.com)"}},
+		},
+		{
+			name:  "no emails to match",
+			data:  workListing,
+			keyID: "B334B7A3196A3020", now: listed,
+		},
+	}
+
+	for _, tt := range tests {
+		keys, err := ParseColons([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: ParseColons() error = %v", tt.name, err)
+			continue
+		}
+		if got := CheckSigningKey(keys, tt.keyID, tt.emails, tt.now); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: CheckSigningKey() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package gpg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/mail"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key is a secret key in the local keyring: a primary key and its subkeys
+type Key struct {
+	Subkey
+	UIDs    []UID
+	Subkeys []Subkey
+}
+
+// Subkey holds the fields shared by primary keys and subkeys
+type Subkey struct {
+	KeyID        string
+	Fingerprint  string
+	Validity     string
+	Capabilities string
+	Created      time.Time
+	// Expires is zero for keys that never expire
+	Expires time.Time
+	// SecretAvailable is false for stubs whose secret part lives elsewhere
+	// (or nowhere), e.g. after exporting only the subkeys
+	SecretAvailable bool
+}
+
+// UID is a user ID on a key
+type UID struct {
+	Validity string
+	Name     string
+	Email    string
+}
+
+// Available reports whether the gpg binary can be found
+func Available() bool {
+	_, err := exec.LookPath("gpg")
+	return err == nil
+}
+
+// ListSecretKeys returns the secret keys in the local keyring
+func ListSecretKeys() ([]*Key, error) {
+	cmd := exec.Command("gpg", "--batch", "--list-secret-keys", "--with-colons", "--fixed-list-mode")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GPG secret keys: %w\nOutput: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return ParseColons(output)
+}
+
+// ParseColons parses gpg's --with-colons key listing. Record types other than
+// sec, ssb, fpr and uid are ignored.
+func ParseColons(data []byte) ([]*Key, error) {
+	var keys []*Key
+	var current *Key
+	var last *Subkey
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "sec", "pub":
+			key, err := parseKeyRecord(fields)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			current = &Key{Subkey: key}
+			keys = append(keys, current)
+			last = &current.Subkey
+		case "ssb", "sub":
+			if current == nil {
+				return nil, fmt.Errorf("line %d: subkey record before any key", lineNum)
+			}
+			key, err := parseKeyRecord(fields)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			current.Subkeys = append(current.Subkeys, key)
+			last = &current.Subkeys[len(current.Subkeys)-1]
+		case "fpr":
+			if last != nil && last.Fingerprint == "" && len(fields) > 9 {
+				last.Fingerprint = strings.ToUpper(fields[9])
+			}
+		case "uid":
+			if current == nil {
+				return nil, fmt.Errorf("line %d: uid record before any key", lineNum)
+			}
+			current.UIDs = append(current.UIDs, parseUID(fields))
+			last = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// parseKeyRecord parses a sec/ssb/pub/sub record
+func parseKeyRecord(fields []string) (Subkey, error) {
+	field := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	created, err := parseTimestamp(field(5))
+	if err != nil {
+		return Subkey{}, fmt.Errorf("invalid creation date: %w", err)
+	}
+	expires, err := parseTimestamp(field(6))
+	if err != nil {
+		return Subkey{}, fmt.Errorf("invalid expiration date: %w", err)
+	}
+
+	// Field 15 is "#" for a secret key stub; for public listings there is
+	// no secret at all
+	serial := field(14)
+	secret := (fields[0] == "sec" || fields[0] == "ssb") && serial != "#"
+
+	return Subkey{
+		KeyID:           strings.ToUpper(field(4)),
+		Validity:        field(1),
+		Capabilities:    field(11),
+		Created:         created,
+		Expires:         expires,
+		SecretAvailable: secret,
+	}, nil
+}
+
+// parseTimestamp parses a colon-listing date: seconds since the epoch, or an
+// ISO 8601 timestamp from older gpg versions. Empty means no date.
+func parseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Parse("20060102T150405", value)
+}
+
+// parseUID parses a uid record; field 10 holds "Name (Comment) <email>" with
+// colons and special characters escaped as \xNN
+func parseUID(fields []string) UID {
+	uid := UID{Validity: fields[1]}
+	if len(fields) < 10 {
+		return uid
+	}
+
+	userID := unescape(fields[9])
+	if address, err := mail.ParseAddress(userID); err == nil {
+		uid.Name = address.Name
+		uid.Email = address.Address
+		return uid
+	}
+
+	// Fall back to the last <...> part for user IDs mail cannot parse
+	if start, end := strings.LastIndex(userID, "<"), strings.LastIndex(userID, ">"); start >= 0 && end > start {
+		uid.Name = strings.TrimSpace(userID[:start])
+		uid.Email = userID[start+1 : end]
+	} else {
+		uid.Name = userID
+	}
+	return uid
+}
+
+// unescape decodes the \xNN escapes gpg uses in colon listings
+func unescape(value string) string {
+	if !strings.Contains(value, `\x`) {
+		return value
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+3 < len(value) && value[i+1] == 'x' {
+			if b, err := strconv.ParseUint(value[i+2:i+4], 16, 8); err == nil {
+				out.WriteByte(byte(b))
+				i += 3
+				continue
+			}
+		}
+		out.WriteByte(value[i])
+	}
+	return out.String()
+}
+
+// Revoked reports whether the key has been revoked
+func (s *Subkey) Revoked() bool {
+	return s.Validity == "r"
+}
+
+// Expired reports whether the key had expired at now
+func (s *Subkey) Expired(now time.Time) bool {
+	return s.Validity == "e" || (!s.Expires.IsZero() && !s.Expires.After(now))
+}
+
+// CanSign reports whether the key itself has the signing capability
+func (s *Subkey) CanSign() bool {
+	return strings.Contains(s.Capabilities, "s")
+}
+
+// matchesID reports whether id names this key: a fingerprint, or a long or
+// short key ID, optionally with a 0x prefix or a trailing "!"
+func (s *Subkey) matchesID(id string) bool {
+	if s.Fingerprint != "" && strings.HasSuffix(s.Fingerprint, id) {
+		return true
+	}
+	return s.KeyID != "" && strings.HasSuffix(s.KeyID, id)
+}
+
+// normalizeID upper-cases a key reference and strips decorations
+func normalizeID(id string) string {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	id = strings.TrimPrefix(id, "0X")
+	id = strings.TrimSuffix(id, "!")
+	return strings.ReplaceAll(id, " ", "")
+}
+
+// Find returns the key that id refers to, and the specific primary key or
+// subkey it names
+func Find(keys []*Key, id string) (*Key, *Subkey) {
+	id = normalizeID(id)
+	if id == "" {
+		return nil, nil
+	}
+
+	for _, key := range keys {
+		if key.matchesID(id) {
+			return key, &key.Subkey
+		}
+		for i := range key.Subkeys {
+			if key.Subkeys[i].matchesID(id) {
+				return key, &key.Subkeys[i]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// HasEmail reports whether one of the key's user IDs carries email
+func (k *Key) HasEmail(email string) bool {
+	for _, uid := range k.UIDs {
+		if uid.Email != "" && strings.EqualFold(uid.Email, email) && uid.Validity != "r" {
+			return true
+		}
+	}
+	return false
+}
+
+// Emails returns the email addresses on the key's valid user IDs
+func (k *Key) Emails() []string {
+	var emails []string
+	for _, uid := range k.UIDs {
+		if uid.Email != "" && uid.Validity != "r" {
+			emails = append(emails, uid.Email)
+		}
+	}
+	return emails
+}
+
+// signingKey returns the key gpg would sign with when given the primary key:
+// the primary key if it can sign, else the newest usable signing subkey
+func (k *Key) signingKey(now time.Time) *Subkey {
+	if k.CanSign() && k.SecretAvailable && !k.Revoked() && !k.Expired(now) {
+		return &k.Subkey
+	}
+
+	var best *Subkey
+	for i := range k.Subkeys {
+		sub := &k.Subkeys[i]
+		if !sub.CanSign() || !sub.SecretAvailable || sub.Revoked() || sub.Expired(now) {
+			continue
+		}
+		if best == nil || sub.Created.After(best.Created) {
+			best = sub
+		}
+	}
+	return best
+}
@@ -0,0 +1,211 @@
+package gpg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// workListing is `gpg --list-secret-keys --with-colons --fixed-list-mode` for
+// a certify-only primary key with two signing subkeys, an encryption subkey
+// and a revoked user ID
+const workListing = `sec:u:255:22:97BBE5943394F4AE:1792196049:1855268049::u:::cESC:::+::ed25519:::0:
+fpr:::::::::3355BCBCA547D28E9D70B9EA97BBE5943394F4AE:
+grp:::::::::0E75679CEF0BC84EFDA9F41F0D5271A3881591DD:
+uid:u::::1792196049::B3155DD85AFDC4A9EDAC6025B1890B14800D7F51::Work Person <work@example.com>::::::::::0:
+uid:r::::::BC84E42B992DF9728BCAFBFBA32F6FD07D7547F2::Old Name <old@example.com>::::::::::0:
+ssb:u:255:22:B334B7A3196A3020:1792196051:1823732051:::::s:::+::ed25519::
+fpr:::::::::0DB1FBA3060EC5CC1D6E53DFB334B7A3196A3020:
+grp:::::::::6B6A1AE85574C54C4884208938A7F3D31239FD92:
+ssb:u:255:22:976F57DD3D911D7F:1792196054:1793924054:::::s:::+::ed25519::
+fpr:::::::::1C5088B48DE435D970EEB450976F57DD3D911D7F:
+grp:::::::::FA44C21FC3A144375E36FE0CE375D2D65138AF12:
+ssb:u:255:18:D64FD3231D336DA6:1792196056:1855268056:::::e:::+::cv25519::
+fpr:::::::::8697CDB0BD63F719C78DA2FFD64FD3231D336DA6:
+grp:::::::::EB4D6A862B3210FEBFB8627FA0399668FE2DAB52:
+`
+
+// homeListing is the same listing for a signing primary key whose secret
+// part was removed after exporting only the subkeys
+const homeListing = `sec:u:255:22:40488B06DB0D40DB:1792196071:::u:::scSC:::#::ed25519:::0:
+fpr:::::::::DC64AE74155B845E1521AD8940488B06DB0D40DB:
+grp:::::::::33AFCC4CB933FB648BC1CC7953E6D26C424647EA:
+uid:u::::1792196071::7B6B2CDFC8A0E449F97640E32AB5069C7B253636::Home Person <home@example.com>::::::::::0:
+ssb:u:255:22:7BFC988A0003452E:1792196071:1823732071:::::s:::+::ed25519::
+fpr:::::::::8525A1B82C370AD29287DC097BFC988A0003452E:
+grp:::::::::5BAC323DB5A025AE4141E933E5C4B4DE5B1C6D13:
+ssb:u:255:22:740CB3DCB81B2D3A:1792196071:1823732071:::::s:::+::ed25519::
+fpr:::::::::70763B6B1BA34034CE8F7F38740CB3DCB81B2D3A:
+grp:::::::::F23B5C0790663DF954459D201C509AFBD79F0349:
+`
+
+func unix(seconds int64) time.Time {
+	return time.Unix(seconds, 0).UTC()
+}
+
+func TestParseColons(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []*Key
+		wantErr bool
+	}{
+		{
+			name: "signing subkeys and revoked uid",
+			data: workListing,
+			want: []*Key{{
+				Subkey: Subkey{
+					KeyID: "97BBE5943394F4AE", Fingerprint: "3355BCBCA547D28E9D70B9EA97BBE5943394F4AE",
+					Validity: "u", Capabilities: "cESC", Created: unix(1792196049), Expires: unix(1855268049),
+					SecretAvailable: true,
+				},
+				UIDs: []UID{
+					{Validity: "u", Name: "Work Person", Email: "work@example.com"},
+					{Validity: "r", Name: "Old Name", Email: "old@example.com"},
+				},
+				Subkeys: []Subkey{
+					{
+						KeyID: "B334B7A3196A3020", Fingerprint: "0DB1FBA3060EC5CC1D6E53DFB334B7A3196A3020",
+						Validity: "u", Capabilities: "s", Created: unix(1792196051), Expires: unix(1823732051),
+						SecretAvailable: true,
+					},
+					{
+						KeyID: "976F57DD3D911D7F", Fingerprint: "1C5088B48DE435D970EEB450976F57DD3D911D7F",
+						Validity: "u", Capabilities: "s", Created: unix(1792196054), Expires: unix(1793924054),
+						SecretAvailable: true,
+					},
+					{
+						KeyID: "D64FD3231D336DA6", Fingerprint: "8697CDB0BD63F719C78DA2FFD64FD3231D336DA6",
+						Validity: "u", Capabilities: "e", Created: unix(1792196056), Expires: unix(1855268056),
+						SecretAvailable: true,
+					},
+				},
+			}},
+		},
+		{
+			name: "stub primary key",
+			data: homeListing,
+			want: []*Key{{
+				Subkey: Subkey{
+					KeyID: "40488B06DB0D40DB", Fingerprint: "DC64AE74155B845E1521AD8940488B06DB0D40DB",
+					Validity: "u", Capabilities: "scSC", Created: unix(1792196071),
+				},
+				UIDs: []UID{{Validity: "u", Name: "Home Person", Email: "home@example.com"}},
+				Subkeys: []Subkey{
+					{
+						KeyID: "7BFC988A0003452E", Fingerprint: "8525A1B82C370AD29287DC097BFC988A0003452E",
+						Validity: "u", Capabilities: "s", Created: unix(1792196071), Expires: unix(1823732071),
+						SecretAvailable: true,
+					},
+					{
+						KeyID: "740CB3DCB81B2D3A", Fingerprint: "70763B6B1BA34034CE8F7F38740CB3DCB81B2D3A",
+						Validity: "u", Capabilities: "s", Created: unix(1792196071), Expires: unix(1823732071),
+						SecretAvailable: true,
+					},
+				},
+			}},
+		},
+		{
+			name: "public listing, escaped uid and ISO dates",
+			data: "pub:-:255:22:abcdef0123456789:20261017T120000::::::sc::::::\n" +
+				"uid:-::::::::Team\\x3a Ops <ops@example.com>:\n",
+			want: []*Key{{
+				Subkey: Subkey{
+					KeyID: "ABCDEF0123456789", Validity: "-", Capabilities: "sc",
+					Created: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+				},
+				UIDs: []UID{{Validity: "-", Name: "Team: Ops", Email: "ops@example.com"}},
+			}},
+		},
+		{
+			name:    "subkey before key",
+			data:    "ssb:u:255:22:B334B7A3196A3020:1792196051:::::::s:::+::ed25519::\n",
+			wantErr: true,
+		},
+		{
+			name:    "uid before key",
+			data:    "uid:u::::::::Work Person <work@example.com>:\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			data:    "sec:u:255:22:97BBE5943394F4AE:yesterday:::u:::cESC:::+::ed25519:::0:\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseColons([]byte(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ParseColons() error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ParseColons() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	keys, err := ParseColons([]byte(workListing + homeListing))
+	if err != nil {
+		t.Fatalf("ParseColons() error = %v", err)
+	}
+
+	tests := []struct {
+		id      string
+		wantKey string
+		wantSub string
+	}{
+		{"3355BCBCA547D28E9D70B9EA97BBE5943394F4AE", "97BBE5943394F4AE", "97BBE5943394F4AE"},
+		{"0x97bbe5943394f4ae", "97BBE5943394F4AE", "97BBE5943394F4AE"},
+		{"976F57DD3D911D7F!", "97BBE5943394F4AE", "976F57DD3D911D7F"},
+		{"3D911D7F", "97BBE5943394F4AE", "976F57DD3D911D7F"},
+		{"8525 A1B8 2C37 0AD2 9287  DC09 7BFC 988A 0003 452E", "40488B06DB0D40DB", "7BFC988A0003452E"},
+		{"0123456789ABCDEF", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		key, sub := Find(keys, tt.id)
+		var gotKey, gotSub string
+		if key != nil {
+			gotKey, gotSub = key.KeyID, sub.KeyID
+		}
+		if gotKey != tt.wantKey || gotSub != tt.wantSub {
+			t.Errorf("Find(%q) = %s, %s, want %s, %s", tt.id, gotKey, gotSub, tt.wantKey, tt.wantSub)
+		}
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		now  time.Time
+		want string
+	}{
+		{"newest signing subkey", workListing, unix(1792196062), "976F57DD3D911D7F"},
+		{"skips expired subkey", workListing, unix(1793924054), "B334B7A3196A3020"},
+		{"none once all expired", workListing, unix(1823732051), ""},
+		{"skips revoked subkey", strings.Replace(workListing, "ssb:u:255:22:976F", "ssb:r:255:22:976F", 1), unix(1792196062), "B334B7A3196A3020"},
+		{"skips stub primary", homeListing, unix(1792196072), "7BFC988A0003452E"},
+		{"primary when it can sign", strings.Replace(homeListing, ":#::", ":+::", 1), unix(1792196072), "40488B06DB0D40DB"},
+	}
+
+	for _, tt := range tests {
+		keys, err := ParseColons([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: ParseColons() error = %v", tt.name, err)
+			continue
+		}
+		var got string
+		if sub := keys[0].signingKey(tt.now); sub != nil {
+			got = sub.KeyID
+		}
+		if got != tt.want {
+			t.Errorf("%s: signingKey() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
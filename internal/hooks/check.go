@@ -0,0 +1,170 @@
+package hooks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+)
+
+// CheckCommit verifies that a commit in the repository at path would be
+// authored and committed with an email of the profile the rules select.
+// Repositories no rule covers pass.
+func CheckCommit(cfg *config.Config, path string) error {
+	match, profile, err := selectedProfile(cfg, path)
+	if err != nil || profile == nil {
+		return err
+	}
+
+	var problems []string
+	for _, ident := range []struct{ role, kind string }{
+		{"author", "GIT_AUTHOR_IDENT"},
+		{"committer", "GIT_COMMITTER_IDENT"},
+	} {
+		_, email, err := git.Ident(path, ident.kind)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if !profile.HasEmail(email) {
+			problems = append(problems, fmt.Sprintf("git would record %s as %s", email, ident.role))
+		}
+	}
+
+	return blocked("Commit", match, profile, problems,
+		"Run 'gh-switch which' to see where the identity comes from, or bypass with --no-verify")
+}
+
+// CheckPush verifies a push from the repository at path to url, with the
+// ref updates git passes a pre-push hook on stdin. The URL's owner must be on
+// the allow-list of the profile the rules select, it must not go through
+// another profile's host alias, and every outgoing commit must be authored
+// and committed with one of the profile's emails. Repositories no rule
+// covers pass.
+func CheckPush(cfg *config.Config, path, url string, updates io.Reader) error {
+	match, profile, err := selectedProfile(cfg, path)
+	if err != nil || profile == nil {
+		return err
+	}
+
+	var problems []string
+	hint := "Bypass with --no-verify"
+
+	if parsed, aliasProfile, ok := cfg.ResolveRemoteURL(url); ok {
+		owner := parsed.Owner()
+		switch {
+		case aliasProfile != "" && aliasProfile != profile.Name:
+			problems = append(problems, fmt.Sprintf("%s goes through the SSH host alias of profile '%s'", url, aliasProfile))
+		case !profile.AllowsPushTo(owner):
+			problems = append(problems, fmt.Sprintf("%s belongs to %s, which profile '%s' may not push to (allowed: %s)", url, owner, profile.Name, strings.Join(profile.PushAllowList(), ", ")))
+			hint = fmt.Sprintf("Allow it with 'gh-switch push allow %s %s', or bypass with --no-verify", profile.Name, owner)
+		}
+	}
+
+	commits, err := outgoingCommits(path, updates)
+	if err != nil {
+		return err
+	}
+	for _, commit := range commits {
+		for _, role := range commit.Identities() {
+			if !profile.HasEmail(role[1]) {
+				problems = append(problems, fmt.Sprintf("%.7s %s %s is not an email of profile '%s'", commit.Hash, role[0], role[1], profile.Name))
+			}
+		}
+	}
+
+	return blocked("Push", match, profile, problems, hint)
+}
+
+// outgoingCommits returns the commits a push sends, given the ref updates
+// git passes a pre-push hook: "<local ref> <local sha> <remote ref> <remote sha>"
+// per line
+func outgoingCommits(path string, updates io.Reader) ([]git.Commit, error) {
+	var commits []git.Commit
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(updates)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		localSHA, remoteSHA := fields[1], fields[3]
+
+		// Deleting a remote ref sends no commits
+		if isZeroSHA(localSHA) {
+			continue
+		}
+
+		// Commits the remote already has are not checked: its tip, when
+		// known locally, and anything on a remote-tracking branch. A new
+		// fork has no tracking branches yet but shares its history with
+		// the upstream's.
+		revs := []string{localSHA, "--not", "--remotes"}
+		if !isZeroSHA(remoteSHA) && git.HasCommit(path, remoteSHA) {
+			revs = append(revs, remoteSHA)
+		}
+
+		rangeCommits, err := git.CommitsInRange(path, revs...)
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range rangeCommits {
+			if !seen[commit.Hash] {
+				seen[commit.Hash] = true
+				commits = append(commits, commit)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ref updates: %w", err)
+	}
+	return commits, nil
+}
+
+// isZeroSHA reports whether sha is git's all-zero object name for a missing ref
+func isZeroSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+// selectedProfile returns the rule and profile selected for the repository
+// at path, or a nil profile if no rule covers it
+func selectedProfile(cfg *config.Config, path string) (config.RuleMatch, *config.Profile, error) {
+	gitDir, err := git.RepoGitDir(path)
+	if err != nil {
+		return config.RuleMatch{}, nil, err
+	}
+
+	urls, err := git.RemoteURLs(path)
+	if err != nil {
+		return config.RuleMatch{}, nil, err
+	}
+
+	match, ok := cfg.MatchRule(gitDir, urls)
+	if !ok {
+		return match, nil, nil
+	}
+
+	profile, err := cfg.GetProfile(match.Profile)
+	if err != nil {
+		return match, nil, fmt.Errorf("%s points at missing profile '%s'; run 'gh-switch doctor'", match.Rule, match.Profile)
+	}
+	return match, profile, nil
+}
+
+// blocked formats the message aborting an action, or returns nil if there
+// are no problems
+func blocked(action string, match config.RuleMatch, profile *config.Profile, problems []string, hint string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf(`✗ %s blocked by gh-switch
+  %s selects profile '%s' (%s)
+  %s
+  %s`,
+		action, match.Rule, profile.Name, profile.PrimaryEmail, strings.Join(problems, "\n  "), hint)
+}
@@ -0,0 +1,146 @@
+// Package hooks manages the global git hooks directory through which
+// gh-switch enforces profile identities
+package hooks
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/plan"
+)
+
+// clientHooks are the hooks installed in the managed directory. core.hooksPath
+// replaces each repository's .git/hooks, so every hook a repository may
+// have gets a script that chains to it.
+var clientHooks = []string{
+	"applypatch-msg",
+	"pre-applypatch",
+	"post-applypatch",
+	"pre-commit",
+	"pre-merge-commit",
+	"prepare-commit-msg",
+	"commit-msg",
+	"post-commit",
+	"pre-rebase",
+	"post-checkout",
+	"post-merge",
+	"pre-push",
+	"post-rewrite",
+	"pre-auto-gc",
+}
+
+// checkedHooks are the hooks that run 'gh-switch hooks run' before chaining
+var checkedHooks = map[string]bool{
+	"pre-commit": true,
+	"pre-push":   true,
+}
+
+// stdinHooks are the checked hooks git passes input on stdin, which both
+// gh-switch and the repository's hook need to read
+var stdinHooks = map[string]bool{
+	"pre-push": true,
+}
+
+// IsChecked reports whether gh-switch runs a check in the named hook
+func IsChecked(name string) bool {
+	return checkedHooks[name]
+}
+
+// Manager installs and removes the managed hooks directory
+type Manager struct {
+	dir string
+}
+
+// NewManager creates a hooks manager for ~/.github-switcher/hooks
+func NewManager() (*Manager, error) {
+	dir, err := config.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{dir: dir}, nil
+}
+
+// Dir returns the managed hooks directory
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// Files returns the paths of the managed hook scripts
+func (m *Manager) Files() []string {
+	files := make([]string, 0, len(clientHooks))
+	for _, name := range clientHooks {
+		files = append(files, filepath.Join(m.dir, name))
+	}
+	return files
+}
+
+// PlanInstall schedules writing a script for every client hook. Checked
+// hooks run the gh-switch binary at executable.
+func (m *Manager) PlanInstall(p *plan.Plan, executable string) error {
+	for _, name := range clientHooks {
+		if err := p.WriteFile(filepath.Join(m.dir, name), Script(name, executable), 0755); err != nil {
+			return fmt.Errorf("failed to plan %s hook: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// PlanUninstall schedules removing the managed hook scripts
+func (m *Manager) PlanUninstall(p *plan.Plan) error {
+	for _, name := range clientHooks {
+		if err := p.RemoveFile(filepath.Join(m.dir, name)); err != nil {
+			return fmt.Errorf("failed to plan removing %s hook: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Script renders the shell script for a hook. It runs gh-switch's check for
+// checked hooks, then the repository's own hook of the same name, if any.
+func Script(name, executable string) []byte {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Installed by 'gh-switch hooks install'; reinstalling overwrites this file.\n")
+
+	input := ""
+	if checkedHooks[name] {
+		fmt.Fprintf(&b, "gh_switch=%s\n", shellQuote(executable))
+		b.WriteString(`if [ ! -x "$gh_switch" ]; then
+	echo "gh-switch: $gh_switch not found; run 'gh-switch hooks install' again, or bypass with --no-verify" >&2
+	exit 1
+fi
+`)
+		if stdinHooks[name] {
+			b.WriteString(`stdin=$(mktemp) || exit 1
+trap 'rm -f "$stdin"' EXIT
+cat > "$stdin"
+`)
+			input = ` < "$stdin"`
+		}
+		fmt.Fprintf(&b, "\"$gh_switch\" hooks run %s \"$@\"%s || exit $?\n", name, input)
+	}
+
+	// The repository's hooks live in the common directory, shared by worktrees
+	fmt.Fprintf(&b, "\nhook=\"$(git rev-parse --git-common-dir)/hooks/%s\"\n", name)
+	if input != "" {
+		// Not exec, so the trap removes the buffered input
+		fmt.Fprintf(&b, `if [ -x "$hook" ]; then
+	"$hook" "$@"%s
+	exit $?
+fi
+`, input)
+	} else {
+		b.WriteString(`if [ -x "$hook" ]; then
+	exec "$hook" "$@"
+fi
+`)
+	}
+	return []byte(b.String())
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
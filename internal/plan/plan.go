@@ -0,0 +1,188 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Step is a single reversible change in a plan
+type Step interface {
+	// Describe returns a one-line summary of the change
+	Describe() string
+	// Preview renders the change for --dry-run output
+	Preview() string
+	// Apply performs the change
+	Apply() error
+	// Rollback undoes a change that was successfully applied
+	Rollback() error
+}
+
+// Plan is an ordered change-set spanning config.json, gitconfig files, the
+// SSH config and external commands. Managers add steps to a plan instead of
+// touching files directly, and the plan is then applied all-or-nothing.
+type Plan struct {
+	steps []Step
+	files map[string]*fileStep
+}
+
+// New creates an empty plan
+func New() *Plan {
+	return &Plan{files: make(map[string]*fileStep)}
+}
+
+// Steps returns the planned steps in execution order
+func (p *Plan) Steps() []Step {
+	return p.steps
+}
+
+// Empty reports whether the plan contains no changes
+func (p *Plan) Empty() bool {
+	for _, step := range p.steps {
+		if fs, ok := step.(*fileStep); ok && !fs.changed() {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// Contents returns the content a file will have once the plan is applied
+// so far: pending writes take precedence over what is on disk. The boolean
+// reports whether the file will exist.
+func (p *Plan) Contents(path string) ([]byte, bool, error) {
+	if fs, ok := p.files[path]; ok {
+		return fs.newData, fs.newExists, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, true, nil
+}
+
+// WriteFile schedules path to be replaced with data. Repeated writes to the
+// same path are merged into a single step.
+func (p *Plan) WriteFile(path string, data []byte, perm os.FileMode) error {
+	fs, err := p.fileStep(path)
+	if err != nil {
+		return err
+	}
+	fs.newData = data
+	fs.newExists = true
+	fs.perm = perm
+	return nil
+}
+
+// RemoveFile schedules path to be deleted if it exists
+func (p *Plan) RemoveFile(path string) error {
+	fs, err := p.fileStep(path)
+	if err != nil {
+		return err
+	}
+	fs.newData = nil
+	fs.newExists = false
+	return nil
+}
+
+// Run schedules an external command. The undo commands, if any, are run in
+// order to reverse it during rollback.
+func (p *Plan) Run(args []string, undo ...[]string) {
+	p.steps = append(p.steps, &commandStep{args: args, undo: undo})
+}
+
+// Chmod schedules a permission change on an existing file
+func (p *Plan) Chmod(path string, mode os.FileMode) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+	p.steps = append(p.steps, &chmodStep{path: path, oldMode: info.Mode().Perm(), newMode: mode})
+	return nil
+}
+
+// Add appends a custom step
+func (p *Plan) Add(step Step) {
+	p.steps = append(p.steps, step)
+}
+
+// fileStep returns the pending step for path, creating it on first use
+func (p *Plan) fileStep(path string) (*fileStep, error) {
+	if fs, ok := p.files[path]; ok {
+		return fs, nil
+	}
+
+	fs, err := newFileStep(path)
+	if err != nil {
+		return nil, err
+	}
+	p.files[path] = fs
+	p.steps = append(p.steps, fs)
+	return fs, nil
+}
+
+// Apply executes every step in order. If a step fails, the steps already
+// applied are rolled back in reverse order and the original error returned.
+func (p *Plan) Apply() error {
+	var applied []Step
+
+	for _, step := range p.steps {
+		if fs, ok := step.(*fileStep); ok && !fs.changed() {
+			continue
+		}
+
+		if err := step.Apply(); err != nil {
+			stepErr := fmt.Errorf("%s: %w", step.Describe(), err)
+			if rbErr := rollback(applied); rbErr != nil {
+				return fmt.Errorf("%w (rollback also failed: %v)", stepErr, rbErr)
+			}
+			if len(applied) > 0 {
+				return fmt.Errorf("%w (earlier changes were rolled back)", stepErr)
+			}
+			return stepErr
+		}
+		applied = append(applied, step)
+	}
+
+	return nil
+}
+
+// rollback undoes applied steps in reverse order, continuing past failures
+func rollback(applied []Step) error {
+	var errs []error
+	for i := len(applied) - 1; i >= 0; i-- {
+		if err := applied[i].Rollback(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", applied[i].Describe(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Render writes a human-readable preview of the plan, with file changes
+// shown as unified diffs
+func (p *Plan) Render(w io.Writer) {
+	var previews []string
+	for _, step := range p.steps {
+		if fs, ok := step.(*fileStep); ok && !fs.changed() {
+			continue
+		}
+		previews = append(previews, step.Preview())
+	}
+
+	if len(previews) == 0 {
+		fmt.Fprintln(w, "No changes planned.")
+		return
+	}
+
+	fmt.Fprintf(w, "Planned changes (%d):\n\n", len(previews))
+	for _, preview := range previews {
+		fmt.Fprintln(w, strings.TrimRight(preview, "\n"))
+		fmt.Fprintln(w)
+	}
+}
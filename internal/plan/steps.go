@@ -0,0 +1,179 @@
+package plan
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/fileutil"
+)
+
+// fileStep replaces or removes a file, remembering its original state
+type fileStep struct {
+	path string
+	perm os.FileMode
+
+	oldData   []byte
+	oldExists bool
+	oldPerm   os.FileMode
+
+	newData   []byte
+	newExists bool
+}
+
+// newFileStep captures the current state of path
+func newFileStep(path string) (*fileStep, error) {
+	fs := &fileStep{path: path, perm: 0600}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	fs.oldData = data
+	fs.oldExists = true
+	fs.oldPerm = info.Mode().Perm()
+	fs.newData = data
+	fs.newExists = true
+	return fs, nil
+}
+
+// changed reports whether the step would alter the file
+func (fs *fileStep) changed() bool {
+	return fs.oldExists != fs.newExists || !bytes.Equal(fs.oldData, fs.newData)
+}
+
+func (fs *fileStep) Describe() string {
+	switch {
+	case !fs.newExists:
+		return fmt.Sprintf("remove %s", fs.path)
+	case !fs.oldExists:
+		return fmt.Sprintf("create %s", fs.path)
+	default:
+		return fmt.Sprintf("update %s", fs.path)
+	}
+}
+
+func (fs *fileStep) Preview() string {
+	oldData, newData := fs.oldData, fs.newData
+	if !fs.newExists {
+		newData = nil
+	}
+
+	// Restoring a snapshot can rewrite private keys, which must not be printed
+	if isPrivateKey(oldData) || isPrivateKey(newData) {
+		return fmt.Sprintf("%s (private key, contents not shown)\n", fs.Describe())
+	}
+	return UnifiedDiff(fs.path, fs.oldExists, oldData, fs.newExists, newData)
+}
+
+// isPrivateKey reports whether data is a PEM-encoded private key
+func isPrivateKey(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN ")) && bytes.Contains(data, []byte("PRIVATE KEY-----"))
+}
+
+func (fs *fileStep) Apply() error {
+	if !fs.newExists {
+		if err := os.Remove(fs.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(fs.path), 0700); err != nil {
+		return err
+	}
+	return fileutil.WriteFile(fs.path, fs.newData, fs.perm)
+}
+
+func (fs *fileStep) Rollback() error {
+	if !fs.oldExists {
+		if err := os.Remove(fs.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return fileutil.WriteFile(fs.path, fs.oldData, fs.oldPerm)
+}
+
+// chmodStep changes a file's permission bits
+type chmodStep struct {
+	path    string
+	oldMode os.FileMode
+	newMode os.FileMode
+}
+
+func (cs *chmodStep) Describe() string {
+	return fmt.Sprintf("chmod %s", cs.path)
+}
+
+func (cs *chmodStep) Preview() string {
+	return fmt.Sprintf("$ chmod %o %s  # was %o", cs.newMode, cs.path, cs.oldMode)
+}
+
+func (cs *chmodStep) Apply() error {
+	return os.Chmod(cs.path, cs.newMode)
+}
+
+func (cs *chmodStep) Rollback() error {
+	return os.Chmod(cs.path, cs.oldMode)
+}
+
+// commandStep runs an external command, with optional inverse commands
+type commandStep struct {
+	args []string
+	undo [][]string
+}
+
+func (cs *commandStep) Describe() string {
+	return fmt.Sprintf("run %s", strings.Join(cs.args, " "))
+}
+
+func (cs *commandStep) Preview() string {
+	return "$ " + shellJoin(cs.args)
+}
+
+func (cs *commandStep) Apply() error {
+	return runCommand(cs.args)
+}
+
+func (cs *commandStep) Rollback() error {
+	for _, undo := range cs.undo {
+		if err := runCommand(undo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCommand executes args, including its output in any error
+func runCommand(args []string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// shellJoin quotes arguments that would otherwise be split by a shell
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'$\\*?;&|<>()") {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		} else {
+			quoted[i] = arg
+		}
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,272 @@
+// Package scan finds the git repositories in a directory tree and compares
+// the identity each one resolves against the profile its rules select
+package scan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+)
+
+// Repository kinds
+const (
+	KindRepository = "repository"
+	KindWorktree   = "worktree"
+	KindSubmodule  = "submodule"
+)
+
+// Remote statuses
+const (
+	// RemoteMatches means the remote goes through the profile's alias, or
+	// its owner is routed through the profile
+	RemoteMatches = "match"
+	// RemoteMismatch means the remote belongs to another profile or to a
+	// host the profile doesn't have
+	RemoteMismatch = "mismatch"
+	// RemoteUnknown means the configuration says nothing about the remote
+	RemoteUnknown = "unknown"
+)
+
+// Remote is one of a repository's remote URLs and how it relates to the
+// repository's profile
+type Remote struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
+	// Reason explains a mismatch
+	Reason string `json:"reason,omitempty"`
+}
+
+// Repository is what the scan found out about one repository
+type Repository struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	GitDir string `json:"git_dir"`
+	// Profile is the profile the directory and remote rules select
+	Profile string `json:"profile,omitempty"`
+	// Rule describes the rule that selected the profile
+	Rule string `json:"rule,omitempty"`
+	// Email and Name are the identity git actually resolves
+	Email       string   `json:"email,omitempty"`
+	EmailOrigin string   `json:"email_origin,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Remotes     []Remote `json:"remotes"`
+	// Problems lists every disagreement between the rules and git
+	Problems []string `json:"problems,omitempty"`
+	// Error is set if the repository could not be inspected
+	Error string `json:"error,omitempty"`
+}
+
+// OK reports whether the repository was inspected without problems
+func (r Repository) OK() bool {
+	return r.Error == "" && len(r.Problems) == 0
+}
+
+// Run finds the repositories under root and inspects them, reading
+// directories and running git with at most workers goroutines each.
+// Repositories are returned sorted by path.
+func Run(cfg *config.Config, root string, workers int) ([]Repository, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", root)
+	}
+
+	paths := make(chan string)
+	results := make(chan Repository)
+
+	// Inspecting a repository runs several git commands, so a fixed pool of
+	// workers does it while the walk continues
+	var inspectors sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		inspectors.Add(1)
+		go func() {
+			defer inspectors.Done()
+			for path := range paths {
+				results <- inspect(cfg, path)
+			}
+		}()
+	}
+
+	go func() {
+		walk(root, workers, paths)
+		close(paths)
+		inspectors.Wait()
+		close(results)
+	}()
+
+	var repos []Repository
+	for repo := range results {
+		repos = append(repos, repo)
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Path < repos[j].Path
+	})
+	return repos, nil
+}
+
+// walk sends every directory under root that has a .git entry to found.
+// Subdirectories are walked by up to limit extra goroutines; when none is
+// free, the current goroutine walks them itself. It descends into
+// repositories to find nested ones and submodules, but not into .git.
+func walk(root string, limit int, found chan<- string) {
+	slots := make(chan struct{}, limit)
+	var pending sync.WaitGroup
+
+	var visit func(dir string)
+	visit = func(dir string) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			// Unreadable directories are skipped
+			return
+		}
+
+		for _, entry := range entries {
+			if entry.Name() == ".git" {
+				found <- dir
+				continue
+			}
+			// Symlinks are not followed, so cycles cannot occur
+			if !entry.IsDir() {
+				continue
+			}
+
+			sub := filepath.Join(dir, entry.Name())
+			select {
+			case slots <- struct{}{}:
+				pending.Add(1)
+				go func() {
+					defer pending.Done()
+					defer func() { <-slots }()
+					visit(sub)
+				}()
+			default:
+				visit(sub)
+			}
+		}
+	}
+
+	visit(root)
+	pending.Wait()
+}
+
+// inspect gathers the rule-resolved profile, the effective identity and the
+// remotes of the repository at path
+func inspect(cfg *config.Config, path string) Repository {
+	repo := Repository{Path: path, Kind: KindRepository, Remotes: []Remote{}}
+
+	gitDir, err := git.RepoGitDir(path)
+	if err != nil {
+		repo.Error = err.Error()
+		return repo
+	}
+	repo.GitDir = gitDir
+	repo.Kind = kindOf(gitDir)
+
+	remotes, err := git.Remotes(path)
+	if err != nil {
+		repo.Error = err.Error()
+		return repo
+	}
+
+	urls := make([]string, 0, len(remotes))
+	for _, remote := range remotes {
+		urls = append(urls, remote.URL)
+	}
+
+	if match, ok := cfg.MatchRule(gitDir, urls); ok {
+		repo.Profile, repo.Rule = match.Profile, match.Rule
+	}
+
+	values, err := git.EffectiveConfig(path, "user.email", "user.name")
+	if err != nil {
+		repo.Error = err.Error()
+		return repo
+	}
+	repo.Email, repo.EmailOrigin, repo.Name = values[0].Value, values[0].Origin, values[1].Value
+
+	profile, hasProfile := cfg.Profiles[repo.Profile]
+	switch {
+	case repo.Profile != "" && !hasProfile:
+		repo.Problems = append(repo.Problems, fmt.Sprintf("%s points at missing profile '%s'", repo.Rule, repo.Profile))
+	case hasProfile && !profile.HasEmail(repo.Email):
+		email := repo.Email
+		if email == "" {
+			email = "no email"
+		}
+		expected := profile.PrimaryEmail
+		if len(profile.Emails) > 1 {
+			expected = "one of " + strings.Join(profile.Emails, ", ")
+		}
+		repo.Problems = append(repo.Problems, fmt.Sprintf("git uses %s, profile '%s' expects %s", email, profile.Name, expected))
+	}
+
+	for _, remote := range remotes {
+		status := Remote{Name: remote.Name, URL: remote.URL, Status: RemoteUnknown}
+		if hasProfile {
+			status.Status, status.Reason = remoteStatus(cfg, profile, remote.URL)
+		}
+		if status.Status == RemoteMismatch {
+			repo.Problems = append(repo.Problems, fmt.Sprintf("remote %s %s", remote.Name, status.Reason))
+		}
+		repo.Remotes = append(repo.Remotes, status)
+	}
+
+	return repo
+}
+
+// kindOf tells repositories, linked worktrees and submodules apart by where
+// their git directory lives
+func kindOf(gitDir string) string {
+	slashed := filepath.ToSlash(gitDir)
+	switch {
+	case strings.Contains(slashed, "/.git/worktrees/"):
+		return KindWorktree
+	case strings.Contains(slashed, "/.git/modules/"):
+		return KindSubmodule
+	default:
+		return KindRepository
+	}
+}
+
+// remoteStatus checks a remote URL's host and owner against a profile
+func remoteStatus(cfg *config.Config, profile *config.Profile, url string) (string, string) {
+	parsed, aliasProfile, ok := cfg.ResolveRemoteURL(url)
+	if !ok {
+		return RemoteUnknown, ""
+	}
+
+	if aliasProfile != "" {
+		if aliasProfile != profile.Name {
+			return RemoteMismatch, fmt.Sprintf("goes through profile '%s'", aliasProfile)
+		}
+		return RemoteMatches, ""
+	}
+
+	if _, ok := profile.FindHost(parsed.Host); !ok {
+		return RemoteMismatch, fmt.Sprintf("is on %s, which profile '%s' has no host for", parsed.Host, profile.Name)
+	}
+
+	owner, ok := cfg.ProfileForOrg(parsed.Host, parsed.Owner())
+	switch {
+	case !ok:
+		return RemoteUnknown, ""
+	case owner != profile.Name:
+		return RemoteMismatch, fmt.Sprintf("belongs to %s, which is routed through profile '%s'", parsed.Owner(), owner)
+	default:
+		return RemoteMatches, ""
+	}
+}
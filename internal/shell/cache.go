@@ -0,0 +1,84 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/fileutil"
+)
+
+// cacheFile is where the shell integration keeps its copy of the rules,
+// inside gh-switch's state directory
+const cacheFile = "shell-cache.json"
+
+// cache holds the directory and remote rules with the profiles they select,
+// as of the config.json identified by its modification time and size
+type cache struct {
+	ConfigModTime  int64                      `json:"config_mtime"`
+	ConfigSize     int64                      `json:"config_size"`
+	DirectoryRules []config.DirectoryRule     `json:"directory_rules"`
+	RemoteRules    []config.RemoteRule        `json:"remote_rules"`
+	Profiles       map[string]*config.Profile `json:"profiles"`
+}
+
+// Rules returns the rules and profiles of the configuration. They are read
+// from a cache while config.json is unchanged, so directory changes don't
+// load, validate or migrate the configuration; otherwise the configuration
+// is loaded and the cache rewritten.
+func Rules(configMgr *config.ConfigManager) (*config.Config, error) {
+	stateDir, err := config.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(stateDir, cacheFile)
+
+	var modTime, size int64
+	if info, err := os.Stat(configMgr.ConfigFile()); err == nil {
+		modTime, size = info.ModTime().UnixNano(), info.Size()
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cached cache
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cached) == nil &&
+		cached.ConfigModTime == modTime && cached.ConfigSize == size {
+		return cached.config(), nil
+	}
+
+	cfg, err := configMgr.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	cached = cache{
+		ConfigModTime:  modTime,
+		ConfigSize:     size,
+		DirectoryRules: cfg.DirectoryRules,
+		RemoteRules:    cfg.RemoteRules,
+		Profiles:       cfg.Profiles,
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", cacheFile, err)
+	}
+
+	// A cache that can't be written only costs speed
+	fileutil.WriteFile(path, data, 0600)
+	return cfg, nil
+}
+
+// config returns the cached rules and profiles as a configuration
+func (c *cache) config() *config.Config {
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*config.Profile)
+	}
+	return &config.Config{
+		SchemaVersion:  config.CurrentSchemaVersion,
+		Profiles:       c.Profiles,
+		DirectoryRules: c.DirectoryRules,
+		RemoteRules:    c.RemoteRules,
+	}
+}
@@ -0,0 +1,236 @@
+// Package shell exports a profile's identity as environment variables in
+// interactive shells, following the directory rules as the user changes
+// directory
+package shell
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/git"
+)
+
+// State variables exported alongside the managed ones
+const (
+	// ProfileVar names the profile whose environment is active
+	ProfileVar = "GH_SWITCH_PROFILE"
+	// SavedVar holds the values the managed variables had before the first
+	// profile was activated, so leaving restores them
+	SavedVar = "GH_SWITCH_SAVED"
+)
+
+// Vars are the environment variables the shell integration manages
+var Vars = []string{
+	"GIT_AUTHOR_NAME",
+	"GIT_AUTHOR_EMAIL",
+	"GIT_COMMITTER_NAME",
+	"GIT_COMMITTER_EMAIL",
+	"GIT_SSH_COMMAND",
+	"GH_CONFIG_DIR",
+	"GH_TOKEN",
+}
+
+// Options enable the optional GitHub CLI variables
+type Options struct {
+	// GHConfigDir exports GH_CONFIG_DIR as the profile's gh config directory
+	GHConfigDir bool
+	// GHToken exports GH_TOKEN from the profile's gh config directory
+	GHToken bool
+}
+
+// Change sets a variable, or removes it if Unset is true
+type Change struct {
+	Name  string
+	Value string
+	Unset bool
+}
+
+// Select returns the profile whose environment to export in dir: the one
+// the directory and remote rules select for the enclosing repository, as
+// long as git resolves one of its emails there too. Exporting a profile git
+// disagrees with would override git's choice, so nil is returned instead.
+// Outside repositories git applies no rules, and the profile a repository
+// created in dir would get is returned.
+func Select(cfg *config.Config, dir string) (*config.Profile, error) {
+	gitDir, err := git.RepoGitDir(dir)
+	inRepo := err == nil
+	if !inRepo {
+		gitDir = filepath.Join(dir, ".git")
+	}
+
+	var urls []string
+	if inRepo {
+		if urls, err = git.RemoteURLs(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	match, ok := cfg.MatchRule(gitDir, urls)
+	if !ok {
+		return nil, nil
+	}
+	profile, err := cfg.GetProfile(match.Profile)
+	if err != nil {
+		return nil, fmt.Errorf("%s points at missing profile '%s'", match.Rule, match.Profile)
+	}
+	if !inRepo {
+		return profile, nil
+	}
+
+	values, err := git.EffectiveConfig(dir, "user.email")
+	if err != nil {
+		return nil, err
+	}
+	if values[0].Set && !profile.HasEmail(values[0].Value) {
+		return nil, nil
+	}
+	return profile, nil
+}
+
+// Transition returns the changes that move a shell's environment, read with
+// lookup, to profile's environment, or back to the saved environment if
+// profile is nil. It returns no changes when that profile is already active.
+// Problems that only leave out an optional variable are returned as
+// warnings.
+func Transition(lookup func(string) (string, bool), profile *config.Profile, opts Options) ([]Change, []string, error) {
+	current, active := lookup(ProfileVar)
+	if !active && profile == nil || active && profile != nil && current == profile.Name {
+		return nil, nil, nil
+	}
+
+	saved := make(map[string]*string)
+	if active {
+		encoded, _ := lookup(SavedVar)
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err == nil {
+			err = json.Unmarshal(data, &saved)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", SavedVar, err)
+		}
+	} else {
+		for _, name := range Vars {
+			if value, ok := lookup(name); ok {
+				saved[name] = &value
+			}
+		}
+	}
+
+	restore := func(name string) Change {
+		if value := saved[name]; value != nil {
+			return Change{Name: name, Value: *value}
+		}
+		return Change{Name: name, Unset: true}
+	}
+
+	var changes []Change
+	if profile == nil {
+		for _, name := range Vars {
+			changes = append(changes, restore(name))
+		}
+		changes = append(changes, Change{Name: ProfileVar, Unset: true}, Change{Name: SavedVar, Unset: true})
+		return changes, nil, nil
+	}
+
+	env, warnings := ProfileEnv(profile, opts)
+	for _, name := range Vars {
+		if value, ok := env[name]; ok {
+			changes = append(changes, Change{Name: name, Value: value})
+		} else {
+			changes = append(changes, restore(name))
+		}
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode %s: %w", SavedVar, err)
+	}
+	changes = append(changes,
+		Change{Name: ProfileVar, Value: profile.Name},
+		Change{Name: SavedVar, Value: base64.StdEncoding.EncodeToString(data)},
+	)
+	return changes, warnings, nil
+}
+
+// ProfileEnv returns the managed variables a profile sets. Variables the
+// profile has no value for are left out.
+func ProfileEnv(profile *config.Profile, opts Options) (map[string]string, []string) {
+	env := map[string]string{
+		"GIT_AUTHOR_EMAIL":    profile.PrimaryEmail,
+		"GIT_COMMITTER_EMAIL": profile.PrimaryEmail,
+	}
+	if profile.GitName != "" {
+		env["GIT_AUTHOR_NAME"] = profile.GitName
+		env["GIT_COMMITTER_NAME"] = profile.GitName
+	}
+	if command, ok := git.SSHCommand(profile); ok {
+		env["GIT_SSH_COMMAND"] = command
+	}
+
+	if !opts.GHConfigDir && !opts.GHToken {
+		return env, nil
+	}
+
+	var warnings []string
+	dir, err := GHConfigDir(profile.Name)
+	if err != nil {
+		return env, []string{err.Error()}
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return env, []string{fmt.Sprintf("no gh config for profile '%s'; log in with: GH_CONFIG_DIR=%s gh auth login", profile.Name, dir)}
+	}
+
+	if opts.GHConfigDir {
+		env["GH_CONFIG_DIR"] = dir
+	}
+	if opts.GHToken {
+		if token, err := ghToken(dir, profile.PrimaryHost().HostName); err != nil {
+			warnings = append(warnings, fmt.Sprintf("no GH_TOKEN for profile '%s': %v", profile.Name, err))
+		} else {
+			env["GH_TOKEN"] = token
+		}
+	}
+	return env, warnings
+}
+
+// GHConfigDir returns the gh config directory for a profile:
+// $XDG_CONFIG_HOME/gh-<profile>, or ~/.config/gh-<profile>
+func GHConfigDir(profileName string) (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "gh-"+profileName), nil
+}
+
+// ghToken asks gh for the token stored in a config directory
+func ghToken(dir, hostname string) (string, error) {
+	cmd := exec.Command("gh", "auth", "token", "--hostname", hostname)
+
+	// Tokens in the environment take precedence over the stored one
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		switch name {
+		case "GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "GH_CONFIG_DIR":
+			continue
+		}
+		cmd.Env = append(cmd.Env, kv)
+	}
+	cmd.Env = append(cmd.Env, "GH_CONFIG_DIR="+dir)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("gh auth token failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,104 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported shells
+const (
+	Bash = "bash"
+	Zsh  = "zsh"
+	Fish = "fish"
+)
+
+// Shells lists the supported shells
+var Shells = []string{Bash, Zsh, Fish}
+
+// Init returns the snippet a shell evaluates at startup. It runs
+// "<executable> shell-env <shell> <args>" whenever the working directory
+// changes and evaluates the result.
+func Init(shell, executable string, args []string) (string, error) {
+	q := quote
+	if shell == Fish {
+		q = quoteFish
+	}
+	command := q(executable) + " shell-env " + shell
+	for _, arg := range args {
+		command += " " + q(arg)
+	}
+
+	switch shell {
+	case Bash:
+		// The prompt hook catches every way of changing directory; the
+		// directory check keeps it from running gh-switch on every prompt
+		return fmt.Sprintf(`_gh_switch_hook() {
+  local status=$?
+  if [ "$PWD" != "${_GH_SWITCH_PWD-}" ]; then
+    _GH_SWITCH_PWD=$PWD
+    eval "$(%s)"
+  fi
+  return $status
+}
+if [[ ";${PROMPT_COMMAND[*]:-};" != *";_gh_switch_hook;"* ]]; then
+  if [[ "$(declare -p PROMPT_COMMAND 2>&1)" == "declare -a"* ]]; then
+    PROMPT_COMMAND=(_gh_switch_hook "${PROMPT_COMMAND[@]}")
+  else
+    PROMPT_COMMAND="_gh_switch_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+  fi
+fi
+_gh_switch_hook
+`, command), nil
+	case Zsh:
+		return fmt.Sprintf(`_gh_switch_hook() {
+  eval "$(%s)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _gh_switch_hook
+_gh_switch_hook
+`, command), nil
+	case Fish:
+		return fmt.Sprintf(`function _gh_switch_hook --on-variable PWD
+    %s | source
+end
+_gh_switch_hook
+`, command), nil
+	default:
+		return "", unsupported(shell)
+	}
+}
+
+// Render formats changes as commands for a shell to evaluate
+func Render(shell string, changes []Change) (string, error) {
+	var b strings.Builder
+	for _, change := range changes {
+		switch {
+		case shell == Fish && change.Unset:
+			fmt.Fprintf(&b, "set -e %s;\n", change.Name)
+		case shell == Fish:
+			fmt.Fprintf(&b, "set -gx %s %s;\n", change.Name, quoteFish(change.Value))
+		case shell != Bash && shell != Zsh:
+			return "", unsupported(shell)
+		case change.Unset:
+			fmt.Fprintf(&b, "unset %s;\n", change.Name)
+		default:
+			fmt.Fprintf(&b, "export %s=%s;\n", change.Name, quote(change.Value))
+		}
+	}
+	return b.String(), nil
+}
+
+func unsupported(shell string) error {
+	return fmt.Errorf("unsupported shell '%s' (expected %s)", shell, strings.Join(Shells, ", "))
+}
+
+// quote quotes s for a POSIX shell
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish quotes s for fish, where backslashes and quotes are escaped
+// inside single quotes
+func quoteFish(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -1,13 +1,13 @@
 package ssh
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/calghar/gh-account-switcher/internal/config"
+	"github.com/calghar/gh-account-switcher/internal/plan"
 )
 
 // ConfigManager manages SSH configuration
@@ -37,139 +37,185 @@ func NewConfigManager() (*ConfigManager, error) {
 	}, nil
 }
 
+// ConfigPath returns the path of the user's SSH config
+func (sm *ConfigManager) ConfigPath() string {
+	return sm.sshConfigPath
+}
+
 // EnsureProfileEntry ensures an SSH config entry exists for a profile
 func (sm *ConfigManager) EnsureProfileEntry(profile *config.Profile) error {
-	hostAlias := fmt.Sprintf("github.com-%s", profile.Name)
-	sshKeyFile := filepath.Join(sm.homeDir, ".ssh", fmt.Sprintf("id_%s", profile.Name))
+	unlock, err := config.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p := plan.New()
+	if err := sm.PlanEnsureProfileEntry(p, profile); err != nil {
+		return err
+	}
+	return p.Apply()
+}
 
+// PlanEnsureProfileEntry schedules the profile's managed SSH config block,
+// with one Host entry per profile host, creating it or updating it in place.
+// It also fills in the profile's SSH key path. Host aliases defined outside
+// the block (by the user, for instance) are never touched.
+func (sm *ConfigManager) PlanEnsureProfileEntry(p *plan.Plan, profile *config.Profile) error {
 	// Update profile's SSH key path if not set
 	if profile.SSHKeyPath == "" {
-		profile.SSHKeyPath = sshKeyFile
+		profile.SSHKeyPath = filepath.Join(sm.homeDir, ".ssh", fmt.Sprintf("id_%s", profile.Name))
 	}
 
-	// Check if entry already exists
-	if sm.entryExists(hostAlias) {
-		return nil
+	sshConfig, err := sm.planConfig(p)
+	if err != nil {
+		return err
 	}
 
-	// Create config file if it doesn't exist
-	if _, err := os.Stat(sm.sshConfigPath); os.IsNotExist(err) {
-		if err := os.WriteFile(sm.sshConfigPath, []byte{}, 0600); err != nil {
-			return fmt.Errorf("failed to create SSH config: %w", err)
+	var entries []string
+	for _, host := range profile.HostList() {
+		if sshConfig.DefinesHostOutside(profile.Name, host.HostAlias(profile.Name)) {
+			continue
 		}
+		entries = append(entries, renderHostEntry(profile, host))
 	}
 
-	// Append new entry
-	entry := fmt.Sprintf(`
-# GitHub profile: %s
-Host %s
-    HostName github.com
-    User git
-    IdentityFile %s
-    IdentitiesOnly yes
-`, profile.Name, hostAlias, sshKeyFile)
-
-	file, err := os.OpenFile(sm.sshConfigPath, os.O_APPEND|os.O_WRONLY, 0600)
+	if len(entries) == 0 {
+		_, err = sshConfig.RemoveManagedBlock(profile.Name)
+	} else {
+		err = sshConfig.SetManagedBlock(profile.Name, strings.Join(entries, "\n\n"))
+	}
 	if err != nil {
-		return fmt.Errorf("failed to open SSH config: %w", err)
+		return err
 	}
-	defer file.Close()
 
-	if _, err := file.WriteString(entry); err != nil {
-		return fmt.Errorf("failed to write SSH config entry: %w", err)
+	if err := p.WriteFile(sm.sshConfigPath, sshConfig.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to plan SSH config entry: %w", err)
 	}
 
 	return nil
 }
 
-// entryExists checks if an SSH config entry already exists for a host
-func (sm *ConfigManager) entryExists(hostAlias string) bool {
-	file, err := os.Open(sm.sshConfigPath)
-	if err != nil {
-		return false
+// renderHostEntry builds the Host block for one of a profile's hosts
+func renderHostEntry(profile *config.Profile, host config.Host) string {
+	lines := []string{
+		"Host " + host.HostAlias(profile.Name),
+		"    HostName " + host.HostName,
+		"    User " + host.SSHUser(),
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(line, "Host ") && strings.Contains(line, hostAlias) {
-			return true
-		}
+	if host.Port != 0 {
+		lines = append(lines, fmt.Sprintf("    Port %d", host.Port))
 	}
+	lines = append(lines,
+		"    IdentityFile "+quoteArg(profile.SSHKeyPath),
+		"    IdentitiesOnly yes",
+	)
+	return strings.Join(lines, "\n")
+}
 
-	return false
+// quoteArg quotes an ssh_config argument containing whitespace
+func quoteArg(arg string) string {
+	if strings.ContainsAny(arg, " \t") {
+		return `"` + arg + `"`
+	}
+	return arg
 }
 
-// RemoveProfileEntry removes an SSH config entry for a profile
-func (sm *ConfigManager) RemoveProfileEntry(profileName string) error {
-	hostAlias := fmt.Sprintf("github.com-%s", profileName)
+// planConfig parses the SSH config as it will be once pending plan steps apply
+func (sm *ConfigManager) planConfig(p *plan.Plan) (*Config, error) {
+	data, _, err := p.Contents(sm.sshConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH config: %w", err)
+	}
 
-	file, err := os.Open(sm.sshConfigPath)
+	sshConfig, err := ParseConfig(data)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No config file, nothing to remove
-		}
-		return fmt.Errorf("failed to open SSH config: %w", err)
+		return nil, fmt.Errorf("failed to parse SSH config: %w", err)
+	}
+	return sshConfig, nil
+}
+
+// loadConfig parses an SSH config file; a missing file yields an empty config
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read SSH config: %w", err)
 	}
-	defer file.Close()
 
-	var newLines []string
-	scanner := bufio.NewScanner(file)
-	inTargetEntry := false
-	skipNext := false
+	sshConfig, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return sshConfig, nil
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
+// MissingHostEntries returns the profile hosts whose alias is not defined by
+// the SSH config or any file it includes
+func (sm *ConfigManager) MissingHostEntries(profile *config.Profile) ([]config.Host, error) {
+	sshConfig, err := loadConfig(sm.sshConfigPath)
+	if err != nil {
+		return nil, err
+	}
 
-		// Check for comment marking profile start
-		if strings.HasPrefix(trimmed, "#") && strings.Contains(trimmed, fmt.Sprintf("GitHub profile: %s", profileName)) {
-			skipNext = true
-			continue
+	var included []*Config
+	for _, include := range sshConfig.Includes(filepath.Dir(sm.sshConfigPath)) {
+		includedConfig, err := loadConfig(include)
+		if err != nil {
+			continue // ssh itself ignores unreadable includes
 		}
+		included = append(included, includedConfig)
+	}
 
-		// Check for Host directive
-		if strings.HasPrefix(trimmed, "Host ") {
-			if strings.Contains(trimmed, hostAlias) {
-				inTargetEntry = true
-				continue
-			} else {
-				inTargetEntry = false
-				skipNext = false
-			}
+	var missing []config.Host
+	for _, host := range profile.HostList() {
+		alias := host.HostAlias(profile.Name)
+		defined := sshConfig.DefinesHost(alias)
+		for _, includedConfig := range included {
+			defined = defined || includedConfig.DefinesHost(alias)
 		}
-
-		// Skip lines that are part of target entry or marked to skip
-		if inTargetEntry || skipNext {
-			// If we hit a new Host or non-indented line, stop skipping
-			if strings.HasPrefix(trimmed, "Host ") || (trimmed != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t")) {
-				inTargetEntry = false
-				skipNext = false
-				newLines = append(newLines, line)
-			}
-			continue
+		if !defined {
+			missing = append(missing, host)
 		}
+	}
+
+	return missing, nil
+}
 
-		newLines = append(newLines, line)
+// RemoveProfileEntry removes an SSH config entry for a profile
+func (sm *ConfigManager) RemoveProfileEntry(profileName string) error {
+	unlock, err := config.Lock()
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("failed to read SSH config: %w", err)
+	p := plan.New()
+	if err := sm.PlanRemoveProfileEntry(p, profileName); err != nil {
+		return err
 	}
+	return p.Apply()
+}
 
-	// Write updated config
-	content := strings.Join(newLines, "\n")
-	if err := os.WriteFile(sm.sshConfigPath, []byte(content), 0600); err != nil {
-		return fmt.Errorf("failed to write SSH config: %w", err)
+// PlanRemoveProfileEntry schedules removal of a profile's managed SSH config block
+func (sm *ConfigManager) PlanRemoveProfileEntry(p *plan.Plan, profileName string) error {
+	sshConfig, err := sm.planConfig(p)
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
+	removed, err := sshConfig.RemoveManagedBlock(profileName)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return nil
+	}
 
-// GetHostAlias returns the SSH host alias for a profile
-func GetHostAlias(profileName string) string {
-	return fmt.Sprintf("github.com-%s", profileName)
+	if err := p.WriteFile(sm.sshConfigPath, sshConfig.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to plan SSH config update: %w", err)
+	}
+
+	return nil
 }
 
 // GetSSHKeyPath returns the default SSH key path for a profile
@@ -178,6 +224,15 @@ func GetSSHKeyPath(profileName string) string {
 	return filepath.Join(homeDir, ".ssh", fmt.Sprintf("id_%s", profileName))
 }
 
+// KeyPath returns the private key a profile uses: its own SSH key path, or
+// the default one if it has none
+func KeyPath(profile *config.Profile) string {
+	if profile.SSHKeyPath != "" {
+		return profile.SSHKeyPath
+	}
+	return GetSSHKeyPath(profile.Name)
+}
+
 // CheckSSHKeyExists checks if an SSH key file exists for a profile
 func CheckSSHKeyExists(keyPath string) bool {
 	_, err := os.Stat(keyPath)
@@ -0,0 +1,218 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// GeneratedKey describes a freshly generated key pair. The pair is written
+// to temporary files next to its paths. GeneratedKey is a plan step: applying
+// it moves the pair into place and rolling it back restores the previous
+// pair, so an existing pair survives a failed plan. Discard cleans up once
+// the plan has finished either way.
+type GeneratedKey struct {
+	PrivateKeyPath string
+	PublicKeyPath  string
+	Fingerprint    string
+
+	files []*keyFile
+}
+
+// keyFile is one half of a generated pair: the temporary file holding it and
+// where the file it replaces was moved while the plan runs
+type keyFile struct {
+	path      string
+	tmp       string
+	backup    string
+	installed bool
+}
+
+// GenerateKey creates an Ed25519 key pair in OpenSSH format, to be installed
+// with the private key at keyPath (0600, encrypted when a passphrase is
+// given) and the public key at keyPath.pub (0644). Existing keys are only
+// replaced when force is set.
+func GenerateKey(keyPath, comment string, passphrase []byte, force bool) (*GeneratedKey, error) {
+	pubPath := keyPath + ".pub"
+	if !force {
+		for _, path := range []string{keyPath, pubPath} {
+			if CheckSSHKeyExists(path) {
+				return nil, fmt.Errorf("%s already exists", path)
+			}
+		}
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	var block *pem.Block
+	if len(passphrase) > 0 {
+		block, err = gossh.MarshalPrivateKeyWithPassphrase(privateKey, comment, passphrase)
+	} else {
+		block, err = gossh.MarshalPrivateKey(privateKey, comment)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	sshPublicKey, err := gossh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	authorizedKey := gossh.MarshalAuthorizedKey(sshPublicKey)
+	if comment != "" {
+		authorizedKey = append(authorizedKey[:len(authorizedKey)-1], []byte(" "+comment+"\n")...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	key := &GeneratedKey{
+		PrivateKeyPath: keyPath,
+		PublicKeyPath:  pubPath,
+		Fingerprint:    gossh.FingerprintSHA256(sshPublicKey),
+	}
+	for _, file := range []struct {
+		path string
+		data []byte
+		perm os.FileMode
+	}{
+		{keyPath, pem.EncodeToMemory(block), 0600},
+		{pubPath, authorizedKey, 0644},
+	} {
+		tmp, err := writeTempKeyFile(file.path, file.data, file.perm)
+		if err != nil {
+			key.Discard()
+			return nil, err
+		}
+		key.files = append(key.files, &keyFile{path: file.path, tmp: tmp})
+	}
+	return key, nil
+}
+
+// Describe implements plan.Step
+func (k *GeneratedKey) Describe() string {
+	return fmt.Sprintf("install SSH key pair %s", k.PrivateKeyPath)
+}
+
+// Preview implements plan.Step
+func (k *GeneratedKey) Preview() string {
+	return fmt.Sprintf("install Ed25519 key pair %s (%s)", k.PrivateKeyPath, k.Fingerprint)
+}
+
+// Apply moves the key pair into place. Existing key files are moved aside
+// first, so the step can be rolled back; a failure part way restores them.
+func (k *GeneratedKey) Apply() error {
+	for _, file := range k.files {
+		if err := file.install(); err != nil {
+			k.Rollback()
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback removes the installed pair and moves the previous one back
+func (k *GeneratedKey) Rollback() error {
+	var errs []error
+	for i := len(k.files) - 1; i >= 0; i-- {
+		if err := k.files[i].restore(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Discard deletes whatever temporary files are left: the new pair if it was
+// never installed, or the previous pair once the new one is in place
+func (k *GeneratedKey) Discard() {
+	for _, file := range k.files {
+		for _, path := range []string{file.tmp, file.backup} {
+			if path != "" {
+				os.Remove(path)
+			}
+		}
+	}
+}
+
+// install moves an existing file aside and the new one into its place
+func (f *keyFile) install() error {
+	if _, err := os.Lstat(f.path); err == nil {
+		backup, err := reserveTempPath(f.path, ".bak-*")
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(f.path, backup); err != nil {
+			os.Remove(backup)
+			return fmt.Errorf("failed to move %s aside: %w", f.path, err)
+		}
+		f.backup = backup
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to inspect %s: %w", f.path, err)
+	}
+
+	if err := os.Rename(f.tmp, f.path); err != nil {
+		return fmt.Errorf("failed to install %s: %w", f.path, err)
+	}
+	f.tmp = ""
+	f.installed = true
+	return nil
+}
+
+// restore undoes install, putting the previous file back if there was one
+func (f *keyFile) restore() error {
+	if f.installed {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", f.path, err)
+		}
+		f.installed = false
+	}
+	if f.backup != "" {
+		if err := os.Rename(f.backup, f.path); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", f.path, err)
+		}
+		f.backup = ""
+	}
+	return nil
+}
+
+// reserveTempPath creates an empty file next to path and returns its name
+func reserveTempPath(path, suffix string) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+suffix)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	tmp.Close()
+	return tmp.Name(), nil
+}
+
+// writeTempKeyFile writes a key file next to path with exactly perm,
+// returning the temporary file's path
+func writeTempKeyFile(path string, data []byte, perm os.FileMode) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return tmp.Name(), nil
+}
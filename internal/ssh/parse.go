@@ -0,0 +1,431 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// beginMarker and endMarker delimit the blocks gh-switch owns
+	beginMarker = "# BEGIN gh-switch "
+	endMarker   = "# END gh-switch "
+
+	// legacyMarker preceded the entries written by older releases
+	legacyMarker = "# GitHub profile: "
+)
+
+// Directive is a single "Keyword args..." line
+type Directive struct {
+	Keyword string
+	Args    []string
+	Line    int
+}
+
+// Block is a Host or Match block, or the global directives before the first
+// one (Keyword is empty then). A block runs until the next Host or Match.
+type Block struct {
+	Keyword    string
+	Patterns   []string
+	Directives []Directive
+
+	// Start and End are the block's line range, End exclusive
+	Start int
+	End   int
+	// Managed is the profile owning the block, if it lies in a managed block
+	Managed string
+}
+
+// managedRange is the line range of a "# BEGIN gh-switch" block, inclusive
+// of both markers
+type managedRange struct {
+	profile string
+	begin   int
+	end     int
+}
+
+// Config is a parsed ssh_config file. Lines are kept verbatim; edits only
+// ever touch the managed blocks.
+type Config struct {
+	lines           []string
+	trailingNewline bool
+	// newline joins the lines: "\r\n" for files whose first line ends
+	// with CRLF, otherwise "\n"
+	newline string
+
+	blocks  []*Block
+	managed []managedRange
+}
+
+// ParseConfig parses an ssh_config document
+func ParseConfig(data []byte) (*Config, error) {
+	newline := "\n"
+	if end := strings.IndexByte(string(data), '\n'); end > 0 && data[end-1] == '\r' {
+		newline = "\r\n"
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	c := &Config{trailingNewline: text == "" || strings.HasSuffix(text, "\n"), newline: newline}
+	if text != "" {
+		c.lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	}
+
+	if err := c.reparse(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// reparse rebuilds the block structure from the current lines
+func (c *Config) reparse() error {
+	c.blocks = nil
+	c.managed = nil
+
+	current := &Block{Start: 0}
+	var open *managedRange
+
+	for i, raw := range c.lines {
+		trimmed := strings.TrimSpace(raw)
+
+		if strings.HasPrefix(trimmed, beginMarker) {
+			if open != nil {
+				return fmt.Errorf("line %d: nested gh-switch block inside '%s'", i+1, open.profile)
+			}
+			profile := strings.TrimSpace(strings.TrimPrefix(trimmed, beginMarker))
+			for _, m := range c.managed {
+				if m.profile == profile {
+					return fmt.Errorf("line %d: duplicate gh-switch block for '%s'", i+1, profile)
+				}
+			}
+			open = &managedRange{profile: profile, begin: i}
+			continue
+		}
+		if strings.HasPrefix(trimmed, endMarker) {
+			profile := strings.TrimSpace(strings.TrimPrefix(trimmed, endMarker))
+			if open == nil || open.profile != profile {
+				return fmt.Errorf("line %d: unmatched end of gh-switch block '%s'", i+1, profile)
+			}
+			open.end = i
+			c.managed = append(c.managed, *open)
+			open = nil
+			continue
+		}
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		keyword, args, err := splitDirective(trimmed)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		if strings.EqualFold(keyword, "Host") || strings.EqualFold(keyword, "Match") {
+			current.End = i
+			c.blocks = append(c.blocks, current)
+			current = &Block{Keyword: keyword, Patterns: args, Start: i}
+			if open != nil {
+				current.Managed = open.profile
+			}
+			continue
+		}
+
+		current.Directives = append(current.Directives, Directive{Keyword: keyword, Args: args, Line: i})
+	}
+
+	if open != nil {
+		return fmt.Errorf("unterminated gh-switch block '%s'", open.profile)
+	}
+
+	current.End = len(c.lines)
+	c.blocks = append(c.blocks, current)
+	return nil
+}
+
+// splitDirective splits "Keyword arg..." or "Keyword=arg..." honouring
+// double-quoted arguments
+func splitDirective(line string) (string, []string, error) {
+	var fields []string
+	var b strings.Builder
+	inQuote := false
+	inField := false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '"':
+			inQuote = !inQuote
+			inField = true
+		case !inQuote && (c == ' ' || c == '\t' || (c == '=' && len(fields) == 0)):
+			if inField {
+				fields = append(fields, b.String())
+				b.Reset()
+				inField = false
+			}
+		default:
+			b.WriteByte(c)
+			inField = true
+		}
+	}
+	if inQuote {
+		return "", nil, fmt.Errorf("unterminated quote")
+	}
+	if inField {
+		fields = append(fields, b.String())
+	}
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("empty directive")
+	}
+
+	return fields[0], fields[1:], nil
+}
+
+// Blocks returns the parsed blocks in file order
+func (c *Config) Blocks() []*Block {
+	return c.blocks
+}
+
+// Bytes renders the document with the line endings it was parsed with
+func (c *Config) Bytes() []byte {
+	if len(c.lines) == 0 {
+		return nil
+	}
+	text := strings.Join(c.lines, c.newline)
+	if c.trailingNewline {
+		text += c.newline
+	}
+	return []byte(text)
+}
+
+// DefinesHost reports whether a Host block lists alias as one of its
+// patterns verbatim. Unlike substring checks, github.com-work2 does not
+// define github.com-work.
+func (c *Config) DefinesHost(alias string) bool {
+	for _, block := range c.blocks {
+		if !strings.EqualFold(block.Keyword, "Host") {
+			continue
+		}
+		for _, pattern := range block.Patterns {
+			if pattern == alias {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchesHost reports whether a Host block's patterns match alias the way
+// ssh evaluates them: wildcards apply and any matching negated pattern
+// excludes the block
+func (b *Block) MatchesHost(alias string) bool {
+	if !strings.EqualFold(b.Keyword, "Host") {
+		return false
+	}
+
+	matched := false
+	for _, pattern := range b.Patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		for _, part := range strings.Split(pattern, ",") {
+			if ok, _ := filepath.Match(part, alias); ok || part == alias {
+				if negated {
+					return false
+				}
+				matched = true
+			}
+		}
+	}
+	return matched
+}
+
+// Includes returns the files referenced by Include directives, resolved the
+// way ssh does: ~ is expanded, relative paths are relative to ~/.ssh, and
+// glob patterns are expanded
+func (c *Config) Includes(sshDir string) []string {
+	var paths []string
+	for _, block := range c.blocks {
+		for _, directive := range block.Directives {
+			if !strings.EqualFold(directive.Keyword, "Include") {
+				continue
+			}
+			for _, arg := range directive.Args {
+				pattern := expandSSHPath(arg, sshDir)
+				matches, err := filepath.Glob(pattern)
+				if err != nil {
+					continue
+				}
+				paths = append(paths, matches...)
+			}
+		}
+	}
+	return paths
+}
+
+// expandSSHPath resolves ~ and relative paths in Include arguments
+func expandSSHPath(path, sshDir string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(sshDir, path)
+	}
+	return path
+}
+
+// ManagedBlock returns the body of a profile's managed block, without markers
+func (c *Config) ManagedBlock(profile string) (string, bool) {
+	for _, m := range c.managed {
+		if m.profile == profile {
+			return strings.Join(c.lines[m.begin+1:m.end], "\n"), true
+		}
+	}
+	return "", false
+}
+
+// ManagedProfiles returns the profiles that own a managed block, in file order
+func (c *Config) ManagedProfiles() []string {
+	profiles := make([]string, 0, len(c.managed))
+	for _, m := range c.managed {
+		profiles = append(profiles, m.profile)
+	}
+	return profiles
+}
+
+// DefinesHostOutside reports whether alias is defined by a Host block other
+// than the ones gh-switch wrote for profile, i.e. by the user or by another
+// profile
+func (c *Config) DefinesHostOutside(profile, alias string) bool {
+	legacyStart, legacyEnd, hasLegacy := c.legacyEntry(profile)
+	for _, block := range c.blocks {
+		if block.Managed == profile || (hasLegacy && block.Start >= legacyStart && block.Start < legacyEnd) {
+			continue
+		}
+		if !strings.EqualFold(block.Keyword, "Host") {
+			continue
+		}
+		for _, pattern := range block.Patterns {
+			if pattern == alias {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetManagedBlock writes a profile's managed block. An existing block is
+// updated in place, an entry written by an older release is converted where
+// it stands, and otherwise the block is inserted ahead of any user-authored
+// Host block (such as "Host *") that would also match one of its aliases, so
+// that the profile's settings take precedence.
+func (c *Config) SetManagedBlock(profile, body string) error {
+	body = strings.TrimRight(body, "\n")
+	block := append([]string{beginMarker + profile}, strings.Split(body, "\n")...)
+	block = append(block, endMarker+profile)
+
+	for _, m := range c.managed {
+		if m.profile == profile {
+			return c.splice(m.begin, m.end+1, block)
+		}
+	}
+
+	if start, end, ok := c.legacyEntry(profile); ok {
+		return c.splice(start, end, block)
+	}
+
+	entries, err := ParseConfig([]byte(body))
+	if err != nil {
+		return err
+	}
+	var aliases []string
+	for _, b := range entries.blocks {
+		if strings.EqualFold(b.Keyword, "Host") {
+			aliases = append(aliases, b.Patterns...)
+		}
+	}
+
+	insertAt := len(c.lines)
+	for _, b := range c.blocks {
+		if b.Managed != "" {
+			continue
+		}
+		for _, alias := range aliases {
+			if b.MatchesHost(alias) && b.Start < insertAt {
+				insertAt = b.Start
+			}
+		}
+	}
+
+	// Keep a blank line between the block and its neighbours
+	if insertAt > 0 && strings.TrimSpace(c.lines[insertAt-1]) != "" {
+		block = append([]string{""}, block...)
+	}
+	if insertAt < len(c.lines) {
+		block = append(block, "")
+	}
+	if len(c.lines) == 0 {
+		c.trailingNewline = true
+	}
+
+	return c.splice(insertAt, insertAt, block)
+}
+
+// RemoveManagedBlock deletes a profile's managed block, or the entry an older
+// release wrote for it, reporting whether anything was removed
+func (c *Config) RemoveManagedBlock(profile string) (bool, error) {
+	for _, m := range c.managed {
+		if m.profile == profile {
+			return true, c.splice(c.withLeadingBlank(m.begin), m.end+1, nil)
+		}
+	}
+
+	if start, end, ok := c.legacyEntry(profile); ok {
+		return true, c.splice(c.withLeadingBlank(start), end, nil)
+	}
+
+	return false, nil
+}
+
+// legacyEntry finds the "# GitHub profile: <name>" entry written by older
+// releases: the marker comment and the Host block that follows it
+func (c *Config) legacyEntry(profile string) (int, int, bool) {
+	for _, b := range c.blocks {
+		if b.Managed != "" || !strings.EqualFold(b.Keyword, "Host") {
+			continue
+		}
+
+		marker := b.Start - 1
+		if marker < 0 || strings.TrimSpace(c.lines[marker]) != legacyMarker+profile {
+			continue
+		}
+
+		// The block ends after its last directive; trailing comments and
+		// blank lines belong to whatever follows
+		end := b.Start + 1
+		for _, d := range b.Directives {
+			end = d.Line + 1
+		}
+		return marker, end, true
+	}
+	return 0, 0, false
+}
+
+// withLeadingBlank extends a range start to swallow one preceding blank line
+func (c *Config) withLeadingBlank(start int) int {
+	if start > 0 && strings.TrimSpace(c.lines[start-1]) == "" {
+		return start - 1
+	}
+	return start
+}
+
+// splice replaces lines[start:end] with replacement and reparses
+func (c *Config) splice(start, end int, replacement []string) error {
+	lines := make([]string, 0, len(c.lines)-(end-start)+len(replacement))
+	lines = append(lines, c.lines[:start]...)
+	lines = append(lines, replacement...)
+	lines = append(lines, c.lines[end:]...)
+	c.lines = lines
+	return c.reparse()
+}
@@ -0,0 +1,202 @@
+package ssh
+
+import (
+	"strings"
+	"testing"
+)
+
+const workBody = "Host github.com-work\n  HostName github.com\n  IdentityFile ~/.ssh/id_ed25519_work\n"
+
+const workBlock = "# BEGIN gh-switch work\n" + workBody + "# END gh-switch work\n"
+
+func TestParseConfigRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"lf", "Host a\n  User me\n\n" + workBlock},
+		{"crlf", "# mine\r\nHost a\r\n  User me\r\n\r\n# BEGIN gh-switch work\r\nHost b\r\n# END gh-switch work\r\n"},
+		{"no trailing newline", "Host a\r\n  User me"},
+		{"quoted arguments", "Host a\n  IdentityFile \"~/.ssh/my key\"\n  User=me\n"},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConfig([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: ParseConfig() error = %v", tt.name, err)
+			continue
+		}
+		if got := string(c.Bytes()); got != tt.data {
+			t.Errorf("%s: Bytes() = %q, want %q", tt.name, got, tt.data)
+		}
+	}
+}
+
+func TestSetManagedBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "empty file",
+			data: "",
+			want: workBlock,
+		},
+		{
+			name: "after user entries",
+			data: "Host example.com\n  User me\n",
+			want: "Host example.com\n  User me\n\n" + workBlock,
+		},
+		{
+			name: "ahead of a catch-all host",
+			data: "Host example.com\n  User me\n\nHost *\n  IdentitiesOnly no\n",
+			want: "Host example.com\n  User me\n\n" + workBlock + "\nHost *\n  IdentitiesOnly no\n",
+		},
+		{
+			name: "ahead of a matching wildcard",
+			data: "Host github.com-*\n  User git\n",
+			want: workBlock + "\nHost github.com-*\n  User git\n",
+		},
+		{
+			name: "after a negated host",
+			data: "Host * !github.com-work\n  User me\n",
+			want: "Host * !github.com-work\n  User me\n\n" + workBlock,
+		},
+		{
+			name: "ignores other managed blocks",
+			data: "# BEGIN gh-switch home\nHost *\n  User git\n# END gh-switch home\n",
+			want: "# BEGIN gh-switch home\nHost *\n  User git\n# END gh-switch home\n\n" + workBlock,
+		},
+		{
+			name: "replaces existing block",
+			data: "Host a\n\n# BEGIN gh-switch work\nHost github.com-old\n# END gh-switch work\n\nHost *\n",
+			want: "Host a\n\n" + workBlock + "\nHost *\n",
+		},
+		{
+			name: "converts legacy entry in place",
+			data: "Host a\n  User me\n\n# GitHub profile: work\nHost github.com-work\n  HostName github.com\n  IdentityFile ~/.ssh/old\n\n# mine\nHost b\n",
+			want: "Host a\n  User me\n\n" + workBlock + "\n# mine\nHost b\n",
+		},
+		{
+			name: "crlf",
+			data: "Host a\r\n  User me\r\n",
+			want: "Host a\r\n  User me\r\n\r\n" + strings.ReplaceAll(workBlock, "\n", "\r\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConfig([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: ParseConfig() error = %v", tt.name, err)
+			continue
+		}
+		if err := c.SetManagedBlock("work", workBody); err != nil {
+			t.Errorf("%s: SetManagedBlock() error = %v", tt.name, err)
+			continue
+		}
+		if got := string(c.Bytes()); got != tt.want {
+			t.Errorf("%s: Bytes() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveManagedBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		removed bool
+	}{
+		{
+			name:    "managed block",
+			data:    "Host a\n\n" + workBlock + "\nHost *\n",
+			want:    "Host a\n\nHost *\n",
+			removed: true,
+		},
+		{
+			name:    "only block",
+			data:    workBlock,
+			want:    "",
+			removed: true,
+		},
+		{
+			name:    "legacy entry",
+			data:    "Host a\n\n# GitHub profile: work\nHost github.com-work\n  User git\n# mine\nHost b\n",
+			want:    "Host a\n# mine\nHost b\n",
+			removed: true,
+		},
+		{
+			name:    "other profile",
+			data:    "# GitHub profile: home\nHost github.com-home\n\n# BEGIN gh-switch personal\nHost x\n# END gh-switch personal\n",
+			want:    "# GitHub profile: home\nHost github.com-home\n\n# BEGIN gh-switch personal\nHost x\n# END gh-switch personal\n",
+			removed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConfig([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: ParseConfig() error = %v", tt.name, err)
+			continue
+		}
+		removed, err := c.RemoveManagedBlock("work")
+		if err != nil {
+			t.Errorf("%s: RemoveManagedBlock() error = %v", tt.name, err)
+			continue
+		}
+		if got := string(c.Bytes()); got != tt.want || removed != tt.removed {
+			t.Errorf("%s: RemoveManagedBlock() = %v, %q, want %v, %q", tt.name, removed, got, tt.removed, tt.want)
+		}
+	}
+}
+
+func TestParseConfigMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"well formed", "# BEGIN gh-switch a\nHost a\n# END gh-switch a\n# BEGIN gh-switch b\n# END gh-switch b\n", false},
+		{"nested", "# BEGIN gh-switch a\n# BEGIN gh-switch b\n# END gh-switch b\n# END gh-switch a\n", true},
+		{"duplicate", "# BEGIN gh-switch a\n# END gh-switch a\n# BEGIN gh-switch a\n# END gh-switch a\n", true},
+		{"unmatched end", "# END gh-switch a\n", true},
+		{"mismatched end", "# BEGIN gh-switch a\n# END gh-switch b\n", true},
+		{"unterminated", "# BEGIN gh-switch a\nHost a\n", true},
+		{"unterminated quote", "Host a\n  IdentityFile \"~/.ssh/my key\n", true},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseConfig([]byte(tt.data)); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ParseConfig() error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMatchesHost(t *testing.T) {
+	tests := []struct {
+		line  string
+		alias string
+		want  bool
+	}{
+		{"Host github.com-work", "github.com-work", true},
+		{"Host github.com-work", "github.com-work2", false},
+		{"Host *", "github.com-work", true},
+		{"Host github.com-?ork", "github.com-work", true},
+		{"Host a b github.com-*", "github.com-work", true},
+		{"Host * !github.com-work", "github.com-work", false},
+		{"Host * !github.com-home", "github.com-work", true},
+		{"Match host github.com-work", "github.com-work", false},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConfig([]byte(tt.line + "\n"))
+		if err != nil {
+			t.Fatalf("ParseConfig(%q) error = %v", tt.line, err)
+		}
+		if got := c.Blocks()[1].MatchesHost(tt.alias); got != tt.want {
+			t.Errorf("%q MatchesHost(%q) = %v, want %v", tt.line, tt.alias, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Package wildmatch implements git's wildmatch glob matching, as used by
+// includeIf conditions, so rules can be evaluated exactly as git does
+package wildmatch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Flags change how patterns are matched
+type Flags int
+
+const (
+	// Pathname stops '*', '?' and brackets from matching '/'; only "**"
+	// between slashes crosses directory boundaries
+	Pathname Flags = 1 << iota
+	// CaseFold matches letters case-insensitively
+	CaseFold
+)
+
+type result int
+
+const (
+	match result = iota
+	noMatch
+	abortAll
+	abortToStarStar
+)
+
+// Match reports whether text matches pattern
+func Match(pattern, text string, flags Flags) bool {
+	return dowild(pattern, text, flags) == match
+}
+
+// dowild is a port of git's wildmatch.c
+func dowild(pattern, text string, flags Flags) result {
+	p, t := 0, 0
+	for ; p < len(pattern); p, t = p+1, t+1 {
+		pch := pattern[p]
+		if t == len(text) && pch != '*' {
+			return abortAll
+		}
+
+		var tch byte
+		if t < len(text) {
+			tch = text[t]
+		}
+		if flags&CaseFold != 0 {
+			tch = lower(tch)
+			pch = lower(pch)
+		}
+
+		switch pch {
+		case '\\':
+			// Literal match with the following character
+			p++
+			if p == len(pattern) {
+				return noMatch
+			}
+			pch = pattern[p]
+			if flags&CaseFold != 0 {
+				pch = lower(pch)
+			}
+			if tch != pch {
+				return noMatch
+			}
+
+		case '?':
+			if flags&Pathname != 0 && tch == '/' {
+				return noMatch
+			}
+
+		case '*':
+			var matchSlash bool
+			p++
+			if p < len(pattern) && pattern[p] == '*' {
+				prev := p - 2
+				for p < len(pattern) && pattern[p] == '*' {
+					p++
+				}
+				if flags&Pathname == 0 {
+					matchSlash = true
+				} else if (prev < 0 || pattern[prev] == '/') &&
+					(p == len(pattern) || pattern[p] == '/' ||
+						(pattern[p] == '\\' && p+1 < len(pattern) && pattern[p+1] == '/')) {
+					// "**/" also matches zero directories
+					if p < len(pattern) && pattern[p] == '/' && dowild(pattern[p+1:], text[t:], flags) == match {
+						return match
+					}
+					matchSlash = true
+				}
+				// Otherwise "**" behaves like "*"
+			} else {
+				matchSlash = flags&Pathname == 0
+			}
+
+			if p == len(pattern) {
+				// A trailing star matches the rest, unless it must stop at '/'
+				if !matchSlash && strings.IndexByte(text[t:], '/') >= 0 {
+					return abortToStarStar
+				}
+				return match
+			}
+
+			if !matchSlash && pattern[p] == '/' {
+				// The star can only match up to the next '/', which the
+				// loop then consumes on both sides
+				slash := strings.IndexByte(text[t:], '/')
+				if slash < 0 {
+					return abortAll
+				}
+				t += slash
+				continue
+			}
+
+			for ; t < len(text); t++ {
+				matched := dowild(pattern[p:], text[t:], flags)
+				if matched != noMatch {
+					if !matchSlash || matched != abortToStarStar {
+						return matched
+					}
+				} else if !matchSlash && text[t] == '/' {
+					return abortToStarStar
+				}
+			}
+			return abortAll
+
+		case '[':
+			end, matched, ok := matchBracket(pattern, p, tch, flags)
+			if !ok {
+				return abortAll
+			}
+			if !matched || (flags&Pathname != 0 && tch == '/') {
+				return noMatch
+			}
+			p = end
+
+		default:
+			if tch != pch {
+				return noMatch
+			}
+		}
+	}
+
+	if t < len(text) {
+		return noMatch
+	}
+	return match
+}
+
+// matchBracket matches ch against the bracket expression starting at
+// pattern[start]. It returns the index of the closing ']', whether ch
+// matched, and false if the expression is unterminated.
+func matchBracket(pattern string, start int, ch byte, flags Flags) (int, bool, bool) {
+	p := start + 1
+	if p >= len(pattern) {
+		return 0, false, false
+	}
+
+	negated := false
+	if pattern[p] == '!' || pattern[p] == '^' {
+		negated = true
+		p++
+	}
+
+	matched := false
+	var prev byte
+	for first := true; ; first = false {
+		if p >= len(pattern) {
+			return 0, false, false
+		}
+		c := pattern[p]
+		if c == ']' && !first {
+			break
+		}
+
+		switch {
+		case c == '\\':
+			p++
+			if p >= len(pattern) {
+				return 0, false, false
+			}
+			c = pattern[p]
+			if equalByte(c, ch, flags) {
+				matched = true
+			}
+		case c == '-' && prev != 0 && p+1 < len(pattern) && pattern[p+1] != ']':
+			p++
+			hi := pattern[p]
+			if hi == '\\' {
+				p++
+				if p >= len(pattern) {
+					return 0, false, false
+				}
+				hi = pattern[p]
+			}
+			if prev <= ch && ch <= hi {
+				matched = true
+			} else if flags&CaseFold != 0 && unicode.IsLetter(rune(ch)) {
+				folded := lower(ch)
+				if folded == ch {
+					folded = upper(ch)
+				}
+				if prev <= folded && folded <= hi {
+					matched = true
+				}
+			}
+			c = 0
+		case c == '[' && p+1 < len(pattern) && pattern[p+1] == ':':
+			end := strings.Index(pattern[p+2:], ":]")
+			if end < 0 {
+				// Not a character class: treat '[' literally
+				if equalByte(c, ch, flags) {
+					matched = true
+				}
+				break
+			}
+			class := pattern[p+2 : p+2+end]
+			ok, known := inClass(class, ch, flags)
+			if !known {
+				return 0, false, false
+			}
+			if ok {
+				matched = true
+			}
+			p += end + 3
+			c = 0
+		default:
+			if equalByte(c, ch, flags) {
+				matched = true
+			}
+		}
+
+		prev = c
+		p++
+	}
+
+	return p, matched != negated, true
+}
+
+// inClass reports whether ch belongs to a POSIX character class, and
+// whether the class name is known
+func inClass(class string, ch byte, flags Flags) (bool, bool) {
+	r := rune(ch)
+	switch class {
+	case "alnum":
+		return unicode.IsLetter(r) || unicode.IsDigit(r), true
+	case "alpha":
+		return unicode.IsLetter(r), true
+	case "blank":
+		return ch == ' ' || ch == '\t', true
+	case "cntrl":
+		return unicode.IsControl(r), true
+	case "digit":
+		return unicode.IsDigit(r), true
+	case "graph":
+		return unicode.IsGraphic(r) && ch != ' ', true
+	case "lower":
+		return unicode.IsLower(r) || (flags&CaseFold != 0 && unicode.IsUpper(r)), true
+	case "print":
+		return unicode.IsPrint(r), true
+	case "punct":
+		return unicode.IsPunct(r) || unicode.IsSymbol(r), true
+	case "space":
+		return unicode.IsSpace(r), true
+	case "upper":
+		return unicode.IsUpper(r) || (flags&CaseFold != 0 && unicode.IsLower(r)), true
+	case "xdigit":
+		return strings.IndexByte("0123456789abcdefABCDEF", ch) >= 0, true
+	default:
+		return false, false
+	}
+}
+
+// equalByte compares two bytes, folding case if requested
+func equalByte(a, b byte, flags Flags) bool {
+	if flags&CaseFold != 0 {
+		return lower(a) == lower(b)
+	}
+	return a == b
+}
+
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func upper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
@@ -0,0 +1,135 @@
+package wildmatch
+
+import "testing"
+
+// TestMatch runs vectors from git's t3070-wildmatch.sh. The columns are the
+// expected results for wildmatch (Pathname), iwildmatch (Pathname|CaseFold),
+// pathmatch (no flags) and ipathmatch (CaseFold).
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		text, pattern                      string
+		glob, iglob, pathmatch, pathmatchi bool
+	}{
+		{`foo`, `foo`, true, true, true, true},
+		{`foo`, `bar`, false, false, false, false},
+		{`foo`, `???`, true, true, true, true},
+		{`foo`, `??`, false, false, false, false},
+		{`foo`, `*`, true, true, true, true},
+		{`foo`, `f*`, true, true, true, true},
+		{`foo`, `*f`, false, false, false, false},
+		{`foo`, `*foo*`, true, true, true, true},
+		{`foobar`, `*ob*a*r*`, true, true, true, true},
+		{`aaaaaaabababab`, `*ab`, true, true, true, true},
+		{`foo*`, `foo\*`, true, true, true, true},
+		{`foobar`, `foo\*bar`, false, false, false, false},
+		{`f\oo`, `f\\oo`, true, true, true, true},
+		{`ball`, `*[al]?`, true, true, true, true},
+		{`ten`, `[ten]`, false, false, false, false},
+		{`ten`, `**[!te]`, true, true, true, true},
+		{`ten`, `**[!ten]`, false, false, false, false},
+		{`ten`, `t[a-g]n`, true, true, true, true},
+		{`ten`, `t[!a-g]n`, false, false, false, false},
+		{`ton`, `t[!a-g]n`, true, true, true, true},
+		{`ton`, `t[^a-g]n`, true, true, true, true},
+		{`a]b`, `a[]]b`, true, true, true, true},
+		{`a-b`, `a[]-]b`, true, true, true, true},
+		{`a]b`, `a[]-]b`, true, true, true, true},
+		{`aab`, `a[]-]b`, false, false, false, false},
+		{`aab`, `a[]a-]b`, true, true, true, true},
+		{`]`, `]`, true, true, true, true},
+		{`foo/baz/bar`, `foo*bar`, false, false, true, true},
+		{`foo/baz/bar`, `foo**bar`, false, false, true, true},
+		{`foobazbar`, `foo**bar`, true, true, true, true},
+		{`foo/baz/bar`, `foo/**/bar`, true, true, true, true},
+		{`foo/baz/bar`, `foo/**/**/bar`, true, true, false, false},
+		{`foo/b/a/z/bar`, `foo/**/bar`, true, true, true, true},
+		{`foo/b/a/z/bar`, `foo/**/**/bar`, true, true, true, true},
+		{`foo/bar`, `foo/**/bar`, true, true, false, false},
+		{`foo/bar`, `foo/**/**/bar`, true, true, false, false},
+		{`foo/bar`, `foo?bar`, false, false, true, true},
+		{`foo/bar`, `foo[/]bar`, false, false, true, true},
+		{`foo/bar`, `foo[^a-z]bar`, false, false, true, true},
+		{`foo/bar`, `f[^eiu][^eiu][^eiu][^eiu][^eiu]r`, false, false, true, true},
+		{`foo-bar`, `f[^eiu][^eiu][^eiu][^eiu][^eiu]r`, true, true, true, true},
+		{`foo`, `**/foo`, true, true, false, false},
+		{`XXX/foo`, `**/foo`, true, true, true, true},
+		{`bar/baz/foo`, `**/foo`, true, true, true, true},
+		{`bar/baz/foo`, `*/foo`, false, false, true, true},
+		{`foo/bar/baz`, `**/bar*`, false, false, true, true},
+		{`deep/foo/bar/baz`, `**/bar/*`, true, true, true, true},
+		{`deep/foo/bar`, `**/bar/*`, false, false, false, false},
+		{`foo/bar/baz`, `**/bar**`, false, false, true, true},
+		{`foo/bar/baz/x`, `*/bar/**`, true, true, true, true},
+		{`deep/foo/bar/baz/x`, `*/bar/**`, false, false, true, true},
+		{`deep/foo/bar/baz/x`, `**/bar/*/*`, true, true, true, true},
+		{`acrt`, `a[c-c]st`, false, false, false, false},
+		{`acrt`, `a[c-c]rt`, true, true, true, true},
+		{`]`, `[!]-]`, false, false, false, false},
+		{`a`, `[!]-]`, true, true, true, true},
+		{`-`, `[[-\]]`, false, false, false, false},
+		{`a`, `[[-\]]`, false, false, false, false},
+		{`a1B`, `[[:alpha:]][[:digit:]][[:upper:]]`, true, true, true, true},
+		{`a`, `[[:digit:][:upper:][:space:]]`, false, true, false, true},
+		{`A`, `[[:digit:][:upper:][:space:]]`, true, true, true, true},
+		{`1`, `[[:digit:][:upper:][:space:]]`, true, true, true, true},
+		{`5`, `[[:xdigit:]]`, true, true, true, true},
+		{`f`, `[[:xdigit:]]`, true, true, true, true},
+		{`D`, `[[:xdigit:]]`, true, true, true, true},
+		{`_`, `[[:alnum:][:alpha:][:blank:][:cntrl:][:digit:][:graph:][:lower:][:print:][:punct:][:space:][:upper:][:xdigit:]]`, true, true, true, true},
+		{`5`, `[a-c[:digit:]x-z]`, true, true, true, true},
+		{`b`, `[a-c[:digit:]x-z]`, true, true, true, true},
+		{`y`, `[a-c[:digit:]x-z]`, true, true, true, true},
+		{`q`, `[a-c[:digit:]x-z]`, false, false, false, false},
+		{`-adobe-courier-bold-o-normal--12-120-75-75-m-70-iso8859-1`, `-*-*-*-*-*-*-12-*-*-*-m-*-*-*`, true, true, true, true},
+		{`-adobe-courier-bold-o-normal--12-120-75-75-X-70-iso8859-1`, `-*-*-*-*-*-*-12-*-*-*-m-*-*-*`, false, false, false, false},
+		{`-adobe-courier-bold-o-normal--12-120-75-75-/-70-iso8859-1`, `-*-*-*-*-*-*-12-*-*-*-m-*-*-*`, false, false, false, false},
+		{`XXX/adobe/courier/bold/o/normal//12/120/75/75/X/70/iso8859/1`, `XXX/*/*/*/*/*/*/12/*/*/*/m/*/*/*`, false, false, false, false},
+		{`abcd/abcdefg/abcdefghijk/abcdefghijklmnop.txt`, `**/*a*b*g*n*t`, true, true, true, true},
+		{`abcd/abcdefg/abcdefghijk/abcdefghijklmnop.txtz`, `**/*a*b*g*n*t`, false, false, false, false},
+		{`foo`, `*?`, true, true, true, true},
+		{`foo/bar`, `*/bar`, true, true, true, true},
+		{`foo/bba/arr`, `*/*/*`, true, true, true, true},
+		{`foo/bb/aa/rr`, `**/**/**`, true, true, true, true},
+		{`abcXdefXghi`, `*X*i`, true, true, true, true},
+		{`ab/cXd/efXg/hi`, `*X*i`, false, false, true, true},
+		{`ab/cXd/efXg/hi`, `*/*X*/*/*i`, true, true, true, true},
+		{`ab/cXd/efXg/hi`, `**/*X*/**/*i`, true, true, true, true},
+		{`foo`, `[A-Z]*`, false, true, false, true},
+		{`a`, `[A-Z]`, false, true, false, true},
+		{`A`, `[A-Z]`, true, true, true, true},
+		{`Z`, `[a-z]`, false, true, false, true},
+		{`z`, `[a-z]`, true, true, true, true},
+		{`1`, `[[:upper:]1]`, true, true, true, true},
+		{`a`, `[[:upper:]]`, false, true, false, true},
+		{`A`, `[[:lower:]]`, false, true, false, true},
+		{`a`, `[[:lower:]]`, true, true, true, true},
+		{`\`, `[[-\]]`, true, true, true, true},
+		{`XXX/adobe/courier/bold/o/normal/12/120/75/75/m/70/iso8859/1`, `XXX/*/*/*/*/*/12/*/*/*/m/*/*/*`, true, true, true, true},
+		{`foo/bar/baz/x`, `**/bar/*`, false, false, true, true},
+		{`foo/bar/baz/x`, `**/bar/**`, true, true, true, true},
+		{`foo/bar`, `foo/*/bar`, false, false, false, false},
+		{`foo/x/bar`, `foo/*/bar`, true, true, true, true},
+		{`FOO/bar`, `foo/*`, false, true, false, true},
+		{"", "", true, true, true, true},
+		{`deep/foo/bar/baz/`, `**/bar/*`, false, false, true, true},
+		{`deep/foo/bar/baz/`, `**/bar/**`, true, true, true, true},
+		{`deep/foo/bar/`, `**/bar/**`, true, true, true, true},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []struct {
+			name  string
+			flags Flags
+			want  bool
+		}{
+			{"wildmatch", Pathname, tt.glob},
+			{"iwildmatch", Pathname | CaseFold, tt.iglob},
+			{"pathmatch", 0, tt.pathmatch},
+			{"ipathmatch", CaseFold, tt.pathmatchi},
+		} {
+			if got := Match(tt.pattern, tt.text, mode.flags); got != mode.want {
+				t.Errorf("%s(%q, %q) = %v, want %v", mode.name, tt.pattern, tt.text, got, mode.want)
+			}
+		}
+	}
+}